@@ -2,16 +2,23 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"golang-sql/internal/cache"
 	"golang-sql/internal/config"
 	"golang-sql/internal/database"
+	"golang-sql/internal/media"
+	"golang-sql/internal/outbox"
 	"golang-sql/internal/repository"
 	"golang-sql/internal/server"
+	"golang-sql/internal/stream"
+	"golang-sql/internal/webhook"
+	"golang-sql/internal/worker"
 )
 
 func main() {
@@ -42,14 +49,66 @@ func main() {
 		os.Exit(1)
 	}
 
-	repo, err := repository.NewMySQLProductRepo(db)
+	replicas, err := database.ConnectReplicas(cfg.Database, logger)
+	if err != nil {
+		logger.Error("database_replica_connect_failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	reads := repository.NewReadRouter(db, replicas, cfg.Database.ReplicaMaxLag, logger)
+	defer reads.Close()
+	if len(replicas) > 0 {
+		go worker.ReplicaMonitor(ctx, reads, cfg.Database.ReplicaCheckInterval)
+	}
+
+	repos, err := repository.NewMySQLRepositories(db, reads)
 	if err != nil {
 		logger.Error("repository_init_failed", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
-	defer repo.Close()
+	defer repos.Close()
+
+	cacheStore, err := newCacheStore(ctx, cfg.Cache)
+	if err != nil {
+		logger.Error("cache_init_failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	// The stream hub compares the stats it reads to what it last sent, so it
+	// reads them past the cache, which other writers do not invalidate.
+	products := repos.Products
+	if cacheStore != nil {
+		defer cacheStore.Close()
+		repos.Products = repository.NewCachedProductRepo(repos.Products, cacheStore, cfg.Cache.TTL, logger)
+	}
+
+	store, err := media.NewLocalStore(cfg.Media.Dir)
+	if err != nil {
+		logger.Error("media_store_init_failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	defer store.Close()
 
-	srv, err := server.New(cfg, repo, logger)
+	go worker.ReservationSweeper(ctx, repos.Reservations, cfg.Reservation.SweepInterval, logger)
+	go worker.PriceScheduler(ctx, repos.Prices, cfg.Pricing.ApplyInterval, logger)
+
+	dispatcher := webhook.NewDispatcher(repos.Webhooks, cfg.Webhook, logger)
+	sinks := outbox.Fanout{dispatcher}
+	sink, err := newOutboxSink(cfg.Outbox)
+	if err != nil {
+		logger.Error("outbox_sink_init_failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if sink != nil {
+		sinks = append(sinks, sink)
+	}
+	defer sinks.Close()
+	go worker.OutboxRelay(ctx, repos.Outbox, sinks, cfg.Outbox.RelayInterval, cfg.Outbox.BatchSize, logger)
+	go worker.OutboxPurger(ctx, repos.Outbox, cfg.Outbox.Retention, logger)
+	go worker.WebhookDeliverer(ctx, dispatcher, cfg.Webhook.DeliverInterval, logger)
+
+	hub := stream.NewHub(repos.Outbox, products, cfg.Stream, logger)
+	go hub.Run(ctx)
+
+	srv, err := server.New(cfg, repos, store, hub, logger)
 	if err != nil {
 		logger.Error("server_init_failed", slog.String("error", err.Error()))
 		os.Exit(1)
@@ -84,3 +143,38 @@ func main() {
 		logger.Info("server_stopped_gracefully")
 	}
 }
+
+// newCacheStore returns the store product reads are cached in, or nil when
+// caching is turned off.
+func newCacheStore(ctx context.Context, cfg config.CacheConfig) (cache.Store, error) {
+	switch cfg.Backend {
+	case "memory":
+		return cache.NewLRU(cfg.Size), nil
+	case "redis":
+		return cache.NewRedis(ctx, cfg.RedisURL, cfg.RedisPrefix)
+	case "none", "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", cfg.Backend)
+	}
+}
+
+// newOutboxSink returns the sink product change events are relayed to besides
+// webhook subscriptions, or nil when there is none.
+func newOutboxSink(cfg config.OutboxConfig) (outbox.Sink, error) {
+	switch cfg.Sink {
+	case "stdout":
+		return outbox.NewStdout(), nil
+	case "file":
+		return outbox.NewFile(cfg.File)
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("outbox webhook sink needs OUTBOX_WEBHOOK_URL")
+		}
+		return outbox.NewWebhook(cfg.WebhookURL, cfg.WebhookTimeout), nil
+	case "none", "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown outbox sink %q", cfg.Sink)
+	}
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRUGetSet(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Fatal("empty cache reported a hit")
+	}
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	c.Set(ctx, "a", []byte("2"), time.Minute)
+	if v, ok, _ := c.Get(ctx, "a"); !ok || string(v) != "2" {
+		t.Fatalf("got %q %v, want the overwritten value", v, ok)
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	c.Set(ctx, "b", []byte("2"), time.Minute)
+	c.Get(ctx, "a") // b is now the least recently used
+	c.Set(ctx, "c", []byte("3"), time.Minute)
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("b was not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := c.Get(ctx, key); !ok {
+			t.Errorf("%s was evicted", key)
+		}
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+
+	c.Set(ctx, "a", []byte("1"), -time.Second)
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Fatal("expired entry was returned")
+	}
+	if c.order.Len() != 0 || len(c.entries) != 0 {
+		t.Error("expired entry was not removed")
+	}
+}
+
+func TestLRUDelete(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(0) // clamped to one entry
+
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	c.Delete(ctx, "a", "missing")
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Error("deleted entry was returned")
+	}
+
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	c.Set(ctx, "b", []byte("2"), time.Minute)
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Error("cache of size 0 held more than one entry")
+	}
+}
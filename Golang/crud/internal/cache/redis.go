@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is a Store on a Redis server, or anything that speaks its protocol
+// (Valkey, KeyDB, Dragonfly). Sharing it lets several instances of the
+// service see each other's invalidations. Keys are namespaced by prefix.
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedis connects to the server at url, e.g. redis://localhost:6379/0,
+// and checks that it answers.
+func NewRedis(ctx context.Context, url, prefix string) (*Redis, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("parsing redis url: %w", err)
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("pinging redis: %w", err)
+	}
+	return &Redis{client: client, prefix: prefix}, nil
+}
+
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	val, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return val, true, nil
+}
+
+func (c *Redis) Set(ctx context.Context, key string, val []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, c.prefix+key, val, ttl).Err()
+}
+
+func (c *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	return c.client.Del(ctx, prefixed...).Err()
+}
+
+func (c *Redis) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,18 @@
+// Package cache holds the byte stores behind the repository caches: an
+// in-process LRU and a client for Redis or any server speaking its protocol.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store keeps encoded values under string keys for a limited time. A missing
+// or expired key is reported by ok being false, not by an error; errors mean
+// the store itself could not be reached.
+type Store interface {
+	Get(ctx context.Context, key string) (val []byte, ok bool, err error)
+	Set(ctx context.Context, key string, val []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	Close() error
+}
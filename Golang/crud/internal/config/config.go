@@ -3,13 +3,25 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	Server    ServerConfig
-	Database  DatabaseConfig
-	RateLimit RateLimitConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	RateLimit   RateLimitConfig
+	Reservation ReservationConfig
+	Pricing     PricingConfig
+	Media       MediaConfig
+	Batch       BatchConfig
+	Import      ImportConfig
+	Export      ExportConfig
+	Compression CompressionConfig
+	Cache       CacheConfig
+	Outbox      OutboxConfig
+	Webhook     WebhookConfig
+	Stream      StreamConfig
 }
 
 type ServerConfig struct {
@@ -31,6 +43,13 @@ type DatabaseConfig struct {
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
 	ConnMaxIdleTime time.Duration
+
+	// ReplicaDSNs are read-only copies of the database that product listings,
+	// lookups and stats may be served from. Credentials and a database name
+	// left out of a DSN are taken from the primary's settings.
+	ReplicaDSNs          []string
+	ReplicaCheckInterval time.Duration
+	ReplicaMaxLag        time.Duration
 }
 
 func (d DatabaseConfig) DSN() string {
@@ -44,6 +63,91 @@ type RateLimitConfig struct {
 	Burst             int
 }
 
+type ReservationConfig struct {
+	DefaultTTL    time.Duration
+	MaxTTL        time.Duration
+	SweepInterval time.Duration
+}
+
+type PricingConfig struct {
+	ApplyInterval time.Duration
+}
+
+type MediaConfig struct {
+	Dir            string
+	MaxUploadBytes int64
+	ThumbnailSize  int
+}
+
+type BatchConfig struct {
+	MaxOperations int
+}
+
+type ImportConfig struct {
+	MaxUploadBytes int64
+	MaxRows        int
+	Timeout        time.Duration
+}
+
+type ExportConfig struct {
+	Timeout time.Duration
+}
+
+type CompressionConfig struct {
+	MinSize int
+}
+
+// CacheConfig selects where product reads are cached: "memory" for an LRU
+// in this process, "redis" for a shared server, or "none" to disable caching.
+// Caching is off by default, since stock and price changes made outside the
+// product repository only show once an entry expires.
+type CacheConfig struct {
+	Backend     string
+	TTL         time.Duration
+	Size        int
+	RedisURL    string
+	RedisPrefix string
+}
+
+// OutboxConfig selects where product change events are relayed besides
+// webhook subscriptions: "stdout", "file", "webhook" (a single fixed URL), or
+// "none".
+type OutboxConfig struct {
+	Sink           string
+	File           string
+	WebhookURL     string
+	WebhookTimeout time.Duration
+	RelayInterval  time.Duration
+	BatchSize      int
+	Retention      time.Duration
+}
+
+// WebhookConfig controls delivery to webhook subscriptions. A delivery is
+// attempted up to MaxAttempts times before it is dead-lettered.
+type WebhookConfig struct {
+	Timeout         time.Duration
+	MaxAttempts     int
+	BackoffBase     time.Duration
+	BackoffMax      time.Duration
+	DeliverInterval time.Duration
+	BatchSize       int
+	Concurrency     int
+}
+
+// StreamConfig controls the live event stream at /api/events. Buffer is how
+// many messages a client may fall behind by before it is disconnected to
+// resume from its Last-Event-ID; one that has missed more than ReplayLimit
+// events is told to reload instead. CommitGrace is how long a gap in the
+// event IDs is waited on for a transaction that has yet to commit before it
+// is taken to be an insert that was rolled back.
+type StreamConfig struct {
+	PollInterval time.Duration
+	Buffer       int
+	Heartbeat    time.Duration
+	ReplayLimit  int
+	CommitGrace  time.Duration
+}
+
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
@@ -64,11 +168,74 @@ func Load() *Config {
 			MaxIdleConns:    getIntEnv("DB_MAX_IDLE_CONNS", 10),
 			ConnMaxLifetime: getDurationEnv("DB_CONN_MAX_LIFETIME", 5*time.Minute),
 			ConnMaxIdleTime: getDurationEnv("DB_CONN_MAX_IDLE_TIME", 1*time.Minute),
+
+			ReplicaDSNs:          getListEnv("DB_REPLICA_DSNS"),
+			ReplicaCheckInterval: getDurationEnv("DB_REPLICA_CHECK_INTERVAL", 5*time.Second),
+			ReplicaMaxLag:        getDurationEnv("DB_REPLICA_MAX_LAG", 10*time.Second),
 		},
 		RateLimit: RateLimitConfig{
 			RequestsPerSecond: getFloatEnv("RATE_LIMIT_RPS", 50),
 			Burst:             getIntEnv("RATE_LIMIT_BURST", 100),
 		},
+		Reservation: ReservationConfig{
+			DefaultTTL:    getDurationEnv("RESERVATION_DEFAULT_TTL", 15*time.Minute),
+			MaxTTL:        getDurationEnv("RESERVATION_MAX_TTL", 2*time.Hour),
+			SweepInterval: getDurationEnv("RESERVATION_SWEEP_INTERVAL", 30*time.Second),
+		},
+		Pricing: PricingConfig{
+			ApplyInterval: getDurationEnv("PRICE_APPLY_INTERVAL", 30*time.Second),
+		},
+		Media: MediaConfig{
+			Dir:            getEnv("MEDIA_DIR", "data/media"),
+			MaxUploadBytes: int64(getIntEnv("MEDIA_MAX_UPLOAD_BYTES", 5<<20)),
+			ThumbnailSize:  getIntEnv("MEDIA_THUMBNAIL_SIZE", 320),
+		},
+		Batch: BatchConfig{
+			MaxOperations: getIntEnv("BATCH_MAX_OPERATIONS", 500),
+		},
+		Import: ImportConfig{
+			MaxUploadBytes: int64(getIntEnv("IMPORT_MAX_UPLOAD_BYTES", 10<<20)),
+			MaxRows:        getIntEnv("IMPORT_MAX_ROWS", 10000),
+			Timeout:        getDurationEnv("IMPORT_TIMEOUT", 5*time.Minute),
+		},
+		Export: ExportConfig{
+			Timeout: getDurationEnv("EXPORT_TIMEOUT", 5*time.Minute),
+		},
+		Compression: CompressionConfig{
+			MinSize: getIntEnv("COMPRESS_MIN_SIZE", 1024),
+		},
+		Cache: CacheConfig{
+			Backend:     getEnv("CACHE_BACKEND", "none"),
+			TTL:         getDurationEnv("CACHE_TTL", 15*time.Second),
+			Size:        getIntEnv("CACHE_SIZE", 10000),
+			RedisURL:    getEnv("CACHE_REDIS_URL", "redis://127.0.0.1:6379/0"),
+			RedisPrefix: getEnv("CACHE_REDIS_PREFIX", "storehub:"),
+		},
+		Outbox: OutboxConfig{
+			Sink:           getEnv("OUTBOX_SINK", "none"),
+			File:           getEnv("OUTBOX_FILE", "data/events.ndjson"),
+			WebhookURL:     getEnv("OUTBOX_WEBHOOK_URL", ""),
+			WebhookTimeout: getDurationEnv("OUTBOX_WEBHOOK_TIMEOUT", 10*time.Second),
+			RelayInterval:  getDurationEnv("OUTBOX_RELAY_INTERVAL", 1*time.Second),
+			BatchSize:      getIntEnv("OUTBOX_BATCH_SIZE", 100),
+			Retention:      getDurationEnv("OUTBOX_RETENTION", 7*24*time.Hour),
+		},
+		Webhook: WebhookConfig{
+			Timeout:         getDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:     getIntEnv("WEBHOOK_MAX_ATTEMPTS", 8),
+			BackoffBase:     getDurationEnv("WEBHOOK_BACKOFF_BASE", 30*time.Second),
+			BackoffMax:      getDurationEnv("WEBHOOK_BACKOFF_MAX", 1*time.Hour),
+			DeliverInterval: getDurationEnv("WEBHOOK_DELIVER_INTERVAL", 2*time.Second),
+			BatchSize:       getIntEnv("WEBHOOK_BATCH_SIZE", 50),
+			Concurrency:     getIntEnv("WEBHOOK_CONCURRENCY", 4),
+		},
+		Stream: StreamConfig{
+			PollInterval: getDurationEnv("STREAM_POLL_INTERVAL", 1*time.Second),
+			Buffer:       getIntEnv("STREAM_BUFFER", 64),
+			Heartbeat:    getDurationEnv("STREAM_HEARTBEAT", 15*time.Second),
+			ReplayLimit:  getIntEnv("STREAM_REPLAY_LIMIT", 1000),
+			CommitGrace:  getDurationEnv("STREAM_COMMIT_GRACE", 10*time.Second),
+		},
 	}
 }
 
@@ -79,6 +246,17 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getListEnv splits a comma-separated variable, dropping empty entries.
+func getListEnv(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
 func getIntEnv(key string, fallback int) int {
 	if v := os.Getenv(key); v != "" {
 		if i, err := strconv.Atoi(v); err == nil {
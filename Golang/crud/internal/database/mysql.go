@@ -3,26 +3,22 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 
 	"golang-sql/internal/config"
 )
 
 func Connect(ctx context.Context, cfg config.DatabaseConfig, logger *slog.Logger) (*sql.DB, error) {
-	db, err := sql.Open("mysql", cfg.DSN())
+	db, err := open(cfg.DSN(), cfg)
 	if err != nil {
 		return nil, fmt.Errorf("opening database: %w", err)
 	}
 
-	db.SetMaxOpenConns(cfg.MaxOpenConns)
-	db.SetMaxIdleConns(cfg.MaxIdleConns)
-	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
-	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
-
 	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
@@ -43,9 +39,94 @@ func Connect(ctx context.Context, cfg config.DatabaseConfig, logger *slog.Logger
 	return db, nil
 }
 
-func RunMigrations(ctx context.Context, db *sql.DB, logger *slog.Logger) error {
-	query := `
-	CREATE TABLE IF NOT EXISTS products (
+// ConnectReplicas opens a pool for each of cfg.ReplicaDSNs, sized like the
+// primary's. A replica that is down is not an error here: it simply stays out
+// of rotation until a health check reaches it.
+func ConnectReplicas(cfg config.DatabaseConfig, logger *slog.Logger) ([]*sql.DB, error) {
+	var replicas []*sql.DB
+	fail := func(err error) ([]*sql.DB, error) {
+		for _, db := range replicas {
+			db.Close()
+		}
+		return nil, err
+	}
+
+	for i, dsn := range cfg.ReplicaDSNs {
+		rc, err := replicaDSN(dsn, cfg)
+		if err != nil {
+			return fail(fmt.Errorf("replica %d: %w", i, err))
+		}
+		db, err := open(rc.FormatDSN(), cfg)
+		if err != nil {
+			return fail(fmt.Errorf("opening replica %d: %w", i, err))
+		}
+		replicas = append(replicas, db)
+
+		logger.Info("database replica configured",
+			slog.Int("replica", i),
+			slog.String("addr", rc.Addr),
+			slog.String("database", rc.DBName),
+		)
+	}
+	return replicas, nil
+}
+
+// replicaDSN parses dsn, filling in the credentials and database name from
+// the primary's settings when it leaves them out. The driver options every
+// query here relies on, such as parsing timestamps, are always set.
+func replicaDSN(dsn string, cfg config.DatabaseConfig) (*mysql.Config, error) {
+	rc, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parsing dsn: %w", err)
+	}
+	primary, err := mysql.ParseDSN(cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("parsing primary dsn: %w", err)
+	}
+
+	if rc.User == "" {
+		rc.User, rc.Passwd = primary.User, primary.Passwd
+	}
+	if rc.DBName == "" {
+		rc.DBName = primary.DBName
+	}
+	rc.ParseTime = primary.ParseTime
+	rc.Loc = primary.Loc
+	rc.Collation = primary.Collation
+	if rc.Params == nil {
+		rc.Params = map[string]string{}
+	}
+	for k, v := range primary.Params {
+		if _, ok := rc.Params[k]; !ok {
+			rc.Params[k] = v
+		}
+	}
+	if rc.Timeout == 0 {
+		rc.Timeout = primary.Timeout
+	}
+	if rc.ReadTimeout == 0 {
+		rc.ReadTimeout = primary.ReadTimeout
+	}
+	if rc.WriteTimeout == 0 {
+		rc.WriteTimeout = primary.WriteTimeout
+	}
+	return rc, nil
+}
+
+func open(dsn string, cfg config.DatabaseConfig) (*sql.DB, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	return db, nil
+}
+
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS products (
 		product_id     INT AUTO_INCREMENT PRIMARY KEY,
 		name           VARCHAR(100) NOT NULL,
 		description    VARCHAR(255) DEFAULT '',
@@ -56,16 +137,327 @@ func RunMigrations(ctx context.Context, db *sql.DB, logger *slog.Logger) error {
 		INDEX idx_name (name),
 		INDEX idx_created_at (created_at),
 		INDEX idx_stock (stock_quantity)
-	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`CREATE TABLE IF NOT EXISTS stock_movements (
+		movement_id   BIGINT AUTO_INCREMENT PRIMARY KEY,
+		product_id    INT NOT NULL,
+		movement_type ENUM('receipt', 'sale', 'adjustment', 'return') NOT NULL,
+		quantity      INT NOT NULL,
+		stock_after   INT NOT NULL,
+		reason        VARCHAR(255) DEFAULT '',
+		actor         VARCHAR(100) NOT NULL,
+		created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_product_created (product_id, created_at),
+		CONSTRAINT fk_stock_movements_product FOREIGN KEY (product_id)
+			REFERENCES products (product_id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`ALTER TABLE products
+		ADD COLUMN reserved_quantity INT NOT NULL DEFAULT 0 AFTER stock_quantity;`,
+	`CREATE TABLE IF NOT EXISTS reservations (
+		reservation_id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		product_id     INT NOT NULL,
+		quantity       INT NOT NULL,
+		status         ENUM('active', 'committed', 'released', 'expired') NOT NULL DEFAULT 'active',
+		expires_at     TIMESTAMP NOT NULL,
+		created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		INDEX idx_status_expires (status, expires_at),
+		CONSTRAINT fk_reservations_product FOREIGN KEY (product_id)
+			REFERENCES products (product_id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`CREATE TABLE IF NOT EXISTS warehouses (
+		warehouse_id INT AUTO_INCREMENT PRIMARY KEY,
+		code         VARCHAR(20) NOT NULL,
+		name         VARCHAR(100) NOT NULL,
+		created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE KEY uq_code (code)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`INSERT IGNORE INTO warehouses (warehouse_id, code, name) VALUES (1, 'MAIN', 'Main store');`,
+	`CREATE TABLE IF NOT EXISTS warehouse_stock (
+		warehouse_id INT NOT NULL,
+		product_id   INT NOT NULL,
+		quantity     INT NOT NULL DEFAULT 0,
+		updated_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		PRIMARY KEY (warehouse_id, product_id),
+		INDEX idx_product (product_id),
+		CONSTRAINT fk_warehouse_stock_warehouse FOREIGN KEY (warehouse_id)
+			REFERENCES warehouses (warehouse_id),
+		CONSTRAINT fk_warehouse_stock_product FOREIGN KEY (product_id)
+			REFERENCES products (product_id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`INSERT IGNORE INTO warehouse_stock (warehouse_id, product_id, quantity)
+		SELECT 1, product_id, stock_quantity FROM products WHERE stock_quantity > 0;`,
+	`ALTER TABLE stock_movements
+		ADD COLUMN warehouse_id INT NOT NULL DEFAULT 1 AFTER product_id,
+		MODIFY movement_type ENUM('receipt', 'sale', 'adjustment', 'return', 'transfer') NOT NULL,
+		ADD CONSTRAINT fk_stock_movements_warehouse FOREIGN KEY (warehouse_id)
+			REFERENCES warehouses (warehouse_id);`,
+	`ALTER TABLE products
+		ADD COLUMN reorder_point    INT NOT NULL DEFAULT 10 AFTER reserved_quantity,
+		ADD COLUMN reorder_quantity INT NOT NULL DEFAULT 0 AFTER reorder_point;`,
+	`CREATE TABLE IF NOT EXISTS suppliers (
+		supplier_id INT AUTO_INCREMENT PRIMARY KEY,
+		name        VARCHAR(100) NOT NULL,
+		email       VARCHAR(255) DEFAULT '',
+		phone       VARCHAR(30) DEFAULT '',
+		created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_name (name)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`CREATE TABLE IF NOT EXISTS purchase_orders (
+		po_id        BIGINT AUTO_INCREMENT PRIMARY KEY,
+		supplier_id  INT NOT NULL,
+		warehouse_id INT NOT NULL,
+		status       ENUM('draft', 'sent', 'partially_received', 'received', 'cancelled') NOT NULL DEFAULT 'draft',
+		notes        VARCHAR(255) DEFAULT '',
+		created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		INDEX idx_status (status),
+		CONSTRAINT fk_purchase_orders_supplier FOREIGN KEY (supplier_id)
+			REFERENCES suppliers (supplier_id),
+		CONSTRAINT fk_purchase_orders_warehouse FOREIGN KEY (warehouse_id)
+			REFERENCES warehouses (warehouse_id)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`CREATE TABLE IF NOT EXISTS purchase_order_items (
+		item_id           BIGINT AUTO_INCREMENT PRIMARY KEY,
+		po_id             BIGINT NOT NULL,
+		product_id        INT NOT NULL,
+		quantity_ordered  INT NOT NULL,
+		quantity_received INT NOT NULL DEFAULT 0,
+		unit_cost         DECIMAL(12,2) NOT NULL DEFAULT 0.00,
+		UNIQUE KEY uq_po_product (po_id, product_id),
+		CONSTRAINT fk_po_items_po FOREIGN KEY (po_id)
+			REFERENCES purchase_orders (po_id) ON DELETE CASCADE,
+		CONSTRAINT fk_po_items_product FOREIGN KEY (product_id)
+			REFERENCES products (product_id)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`CREATE TABLE IF NOT EXISTS orders (
+		order_id     BIGINT AUTO_INCREMENT PRIMARY KEY,
+		reference    VARCHAR(100) DEFAULT '',
+		warehouse_id INT NOT NULL,
+		status       ENUM('pending', 'paid', 'fulfilled', 'cancelled') NOT NULL DEFAULT 'pending',
+		total        DECIMAL(12,2) NOT NULL DEFAULT 0.00,
+		actor        VARCHAR(100) NOT NULL,
+		created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+		INDEX idx_status (status),
+		INDEX idx_created_at (created_at),
+		CONSTRAINT fk_orders_warehouse FOREIGN KEY (warehouse_id)
+			REFERENCES warehouses (warehouse_id)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`CREATE TABLE IF NOT EXISTS order_items (
+		item_id    BIGINT AUTO_INCREMENT PRIMARY KEY,
+		order_id   BIGINT NOT NULL,
+		product_id INT NOT NULL,
+		name       VARCHAR(100) NOT NULL,
+		quantity   INT NOT NULL,
+		unit_price DECIMAL(12,2) NOT NULL,
+		UNIQUE KEY uq_order_product (order_id, product_id),
+		CONSTRAINT fk_order_items_order FOREIGN KEY (order_id)
+			REFERENCES orders (order_id) ON DELETE CASCADE,
+		CONSTRAINT fk_order_items_product FOREIGN KEY (product_id)
+			REFERENCES products (product_id)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`CREATE TABLE IF NOT EXISTS price_history (
+		price_change_id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		product_id      INT NOT NULL,
+		price           DECIMAL(12,2) NOT NULL,
+		source          ENUM('create', 'update', 'schedule') NOT NULL,
+		actor           VARCHAR(100) DEFAULT '',
+		changed_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_product_changed (product_id, changed_at),
+		CONSTRAINT fk_price_history_product FOREIGN KEY (product_id)
+			REFERENCES products (product_id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`INSERT INTO price_history (product_id, price, source, changed_at)
+		SELECT product_id, price, 'create', created_at FROM products;`,
+	`CREATE TABLE IF NOT EXISTS scheduled_prices (
+		schedule_id  BIGINT AUTO_INCREMENT PRIMARY KEY,
+		product_id   INT NOT NULL,
+		price        DECIMAL(12,2) NOT NULL,
+		effective_at TIMESTAMP NOT NULL,
+		status       ENUM('pending', 'applied', 'cancelled') NOT NULL DEFAULT 'pending',
+		actor        VARCHAR(100) NOT NULL,
+		created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		applied_at   TIMESTAMP NULL DEFAULT NULL,
+		INDEX idx_status_effective (status, effective_at),
+		INDEX idx_product (product_id),
+		CONSTRAINT fk_scheduled_prices_product FOREIGN KEY (product_id)
+			REFERENCES products (product_id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`ALTER TABLE products
+		ADD COLUMN status ENUM('draft','active','discontinued','archived') NOT NULL DEFAULT 'active' AFTER reorder_quantity,
+		ADD INDEX idx_status (status);`,
+	`ALTER TABLE products
+		ADD COLUMN parent_id      INT NULL AFTER product_id,
+		ADD COLUMN sku            VARCHAR(64) NULL AFTER parent_id,
+		ADD COLUMN options        JSON NULL AFTER description,
+		ADD COLUMN price_override DECIMAL(12,2) NULL AFTER price,
+		ADD UNIQUE INDEX uq_sku (sku),
+		ADD INDEX idx_parent (parent_id),
+		ADD CONSTRAINT fk_products_parent FOREIGN KEY (parent_id)
+			REFERENCES products (product_id);`,
+	`ALTER TABLE products ADD COLUMN is_bundle TINYINT(1) NOT NULL DEFAULT 0 AFTER status;`,
+	`CREATE TABLE IF NOT EXISTS bundle_components (
+		bundle_id    INT NOT NULL,
+		component_id INT NOT NULL,
+		quantity     INT NOT NULL,
+		PRIMARY KEY (bundle_id, component_id),
+		INDEX idx_component (component_id),
+		CONSTRAINT fk_bundle_components_bundle FOREIGN KEY (bundle_id)
+			REFERENCES products (product_id) ON DELETE CASCADE,
+		CONSTRAINT fk_bundle_components_component FOREIGN KEY (component_id)
+			REFERENCES products (product_id)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`CREATE TABLE IF NOT EXISTS product_images (
+		image_id      BIGINT AUTO_INCREMENT PRIMARY KEY,
+		product_id    INT NOT NULL,
+		storage_key   VARCHAR(255) NOT NULL,
+		thumbnail_key VARCHAR(255) NOT NULL,
+		content_type  VARCHAR(50) NOT NULL,
+		size_bytes    BIGINT NOT NULL,
+		width         INT NOT NULL,
+		height        INT NOT NULL,
+		position      INT NOT NULL DEFAULT 0,
+		created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_product_position (product_id, position),
+		CONSTRAINT fk_product_images_product FOREIGN KEY (product_id)
+			REFERENCES products (product_id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`CREATE TABLE IF NOT EXISTS categories (
+		category_id      INT AUTO_INCREMENT PRIMARY KEY,
+		name             VARCHAR(100) NOT NULL,
+		attribute_schema JSON NOT NULL,
+		created_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE INDEX uq_name (name)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`ALTER TABLE products
+		ADD COLUMN category_id INT NULL AFTER options,
+		ADD CONSTRAINT fk_products_category FOREIGN KEY (category_id)
+			REFERENCES categories (category_id);`,
+	`CREATE TABLE IF NOT EXISTS product_attributes (
+		product_id INT NOT NULL,
+		name       VARCHAR(50) NOT NULL,
+		value_type ENUM('string','number','boolean') NOT NULL,
+		value      VARCHAR(255) NOT NULL,
+		num_value  DECIMAL(20,6) NULL,
+		PRIMARY KEY (product_id, name),
+		INDEX idx_name_value (name, value),
+		INDEX idx_name_num (name, num_value),
+		CONSTRAINT fk_product_attributes_product FOREIGN KEY (product_id)
+			REFERENCES products (product_id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`ALTER TABLE products
+		MODIFY updated_at TIMESTAMP(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6);`,
+	`CREATE TABLE IF NOT EXISTS outbox_events (
+		event_id     BIGINT AUTO_INCREMENT PRIMARY KEY,
+		event_type   VARCHAR(50) NOT NULL,
+		product_id   INT NOT NULL,
+		payload      JSON NULL,
+		created_at   TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		published_at TIMESTAMP(6) NULL,
+		attempts     INT NOT NULL DEFAULT 0,
+		last_error   VARCHAR(255) NOT NULL DEFAULT '',
+		INDEX idx_unpublished (published_at, event_id),
+		INDEX idx_created (created_at)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+		subscription_id INT AUTO_INCREMENT PRIMARY KEY,
+		url             VARCHAR(500) NOT NULL,
+		secret          VARCHAR(100) NOT NULL,
+		events          JSON NOT NULL,
+		active          TINYINT(1) NOT NULL DEFAULT 1,
+		created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+	`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		delivery_id     BIGINT AUTO_INCREMENT PRIMARY KEY,
+		subscription_id INT NOT NULL,
+		event_id        BIGINT NOT NULL,
+		event_type      VARCHAR(50) NOT NULL,
+		payload         JSON NOT NULL,
+		status          ENUM('pending', 'succeeded', 'dead') NOT NULL DEFAULT 'pending',
+		attempts        INT NOT NULL DEFAULT 0,
+		next_attempt_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		response_status INT NOT NULL DEFAULT 0,
+		last_error      VARCHAR(255) NOT NULL DEFAULT '',
+		created_at      TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+		delivered_at    TIMESTAMP(6) NULL,
+		UNIQUE INDEX uq_subscription_event (subscription_id, event_id),
+		INDEX idx_due (status, next_attempt_at),
+		INDEX idx_subscription_created (subscription_id, delivery_id),
+		CONSTRAINT fk_webhook_deliveries_subscription FOREIGN KEY (subscription_id)
+			REFERENCES webhook_subscriptions (subscription_id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+}
+
+func RunMigrations(ctx context.Context, db *sql.DB, logger *slog.Logger) error {
+	if _, err := db.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INT PRIMARY KEY,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	) ENGINE=InnoDB;`); err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current); err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
 
-	if _, err := db.ExecContext(ctx, query); err != nil {
-		return fmt.Errorf("running migration: %w", err)
+	for i := current; i < len(migrations); i++ {
+		version := i + 1
+		if err := applyMigration(ctx, db, version, migrations[i], logger); err != nil {
+			return err
+		}
+		logger.Info("database migration applied", slog.Int("version", version))
 	}
 
-	logger.Info("database migration completed")
+	logger.Info("database migration completed", slog.Int("version", len(migrations)))
 	return nil
 }
 
+// applyMigration runs a migration and records its version in one
+// transaction, so a data migration and its version land together. DDL
+// commits on its own, but MySQL applies each statement atomically: if the
+// process dies before the version is recorded, the statement ran in full,
+// and running it again fails only because what it creates already exists.
+// That failure is taken to mean the migration was applied.
+func applyMigration(ctx context.Context, db *sql.DB, version int, stmt string, logger *slog.Logger) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning migration %d: %w", version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, stmt); err != nil {
+		if !alreadyApplied(err) {
+			return fmt.Errorf("running migration %d: %w", version, err)
+		}
+		logger.Warn("database migration already applied", slog.Int("version", version), slog.String("error", err.Error()))
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES (?)", version); err != nil {
+		return fmt.Errorf("recording migration %d: %w", version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing migration %d: %w", version, err)
+	}
+	return nil
+}
+
+// alreadyApplied reports whether err says a table, column, index or foreign
+// key a migration adds exists already.
+func alreadyApplied(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	switch mysqlErr.Number {
+	case 1050, 1060, 1061, 1826: // table, column, key name, foreign key
+		return true
+	}
+	return false
+}
+
 func SeedIfEmpty(ctx context.Context, db *sql.DB, logger *slog.Logger) error {
 	var count int
 	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products").Scan(&count); err != nil {
@@ -96,6 +488,25 @@ func SeedIfEmpty(ctx context.Context, db *sql.DB, logger *slog.Logger) error {
 		return fmt.Errorf("seeding products: %w", err)
 	}
 
+	if _, err := db.ExecContext(ctx, `
+	INSERT INTO warehouse_stock (warehouse_id, product_id, quantity)
+		SELECT 1, product_id, stock_quantity FROM products WHERE stock_quantity > 0;`); err != nil {
+		return fmt.Errorf("seeding warehouse stock: %w", err)
+	}
+
+	if _, err := db.ExecContext(ctx, `
+	INSERT INTO stock_movements (product_id, warehouse_id, movement_type, quantity, stock_after, reason, actor)
+		SELECT product_id, 1, 'receipt', stock_quantity, stock_quantity, 'initial stock', 'system'
+		FROM products WHERE stock_quantity > 0;`); err != nil {
+		return fmt.Errorf("seeding stock movements: %w", err)
+	}
+
+	if _, err := db.ExecContext(ctx, `
+	INSERT INTO price_history (product_id, price, source)
+		SELECT product_id, price, 'create' FROM products;`); err != nil {
+		return fmt.Errorf("seeding price history: %w", err)
+	}
+
 	logger.Info("database seeded with sample products")
 	return nil
 }
@@ -0,0 +1,116 @@
+// Package export writes products out as CSV, NDJSON or XLSX one row at a
+// time, so a full catalog can be streamed straight to a client.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"golang-sql/internal/model"
+)
+
+// Columns are the fields of a tabular export, named as in the JSON API.
+var Columns = []string{
+	"id", "parent_id", "sku", "name", "description", "category_id",
+	"price", "price_override", "stock_quantity", "reserved_quantity", "available_quantity",
+	"reorder_point", "reorder_quantity", "status", "is_bundle", "created_at", "updated_at",
+}
+
+// Writer writes products in one export format. Close must be called after
+// the last product to complete the file; it does not close the underlying
+// io.Writer.
+type Writer interface {
+	Write(p *model.Product) error
+	Close() error
+}
+
+type Format struct {
+	ContentType string
+	Extension   string
+	New         func(w io.Writer) (Writer, error)
+}
+
+var Formats = map[string]Format{
+	"csv":    {"text/csv; charset=utf-8", ".csv", newCSVWriter},
+	"ndjson": {"application/x-ndjson", ".ndjson", newNDJSONWriter},
+	"xlsx":   {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", ".xlsx", newXLSXWriter},
+}
+
+// row returns the values of p in the order of Columns. Optional fields are
+// nil when unset.
+func row(p *model.Product) []any {
+	var parentID, categoryID, override any
+	if p.ParentID != nil {
+		parentID = *p.ParentID
+	}
+	if p.CategoryID != nil {
+		categoryID = *p.CategoryID
+	}
+	if p.PriceOverride != nil {
+		override = *p.PriceOverride
+	}
+	return []any{
+		p.ID, parentID, p.SKU, p.Name, p.Description, categoryID,
+		p.Price, override, p.StockQty, p.ReservedQty, p.AvailableQty,
+		p.ReorderPoint, p.ReorderQty, string(p.Status), p.IsBundle, p.CreatedAt, p.UpdatedAt,
+	}
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer) (Writer, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(Columns))}
+	if err := cw.w.Write(Columns); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(p *model.Product) error {
+	for i, v := range row(p) {
+		switch v := v.(type) {
+		case nil:
+			cw.record[i] = ""
+		case string:
+			cw.record[i] = v
+		case int:
+			cw.record[i] = strconv.Itoa(v)
+		case int64:
+			cw.record[i] = strconv.FormatInt(v, 10)
+		case float64:
+			cw.record[i] = strconv.FormatFloat(v, 'f', 2, 64)
+		case bool:
+			cw.record[i] = strconv.FormatBool(v)
+		case time.Time:
+			cw.record[i] = v.Format(time.RFC3339)
+		}
+	}
+	return cw.w.Write(cw.record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) (Writer, error) {
+	return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+}
+
+func (nw *ndjsonWriter) Write(p *model.Product) error {
+	return nw.enc.Encode(p)
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
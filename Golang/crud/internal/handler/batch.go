@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+// Batch applies a list of creates, updates and deletes in one request. The
+// response is 200 when every operation succeeded and 207 otherwise, with one
+// result per operation in request order.
+func (h *ProductHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	var req model.BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	if len(req.Operations) == 0 {
+		jsonErr(w, http.StatusUnprocessableEntity, "batch needs at least one operation")
+		return
+	}
+	if len(req.Operations) > h.batch.MaxOperations {
+		jsonErr(w, http.StatusUnprocessableEntity, fmt.Sprintf("batch may hold at most %d operations", h.batch.MaxOperations))
+		return
+	}
+
+	resp := model.BatchResponse{Atomic: req.Atomic, Results: make([]model.BatchResult, len(req.Operations))}
+
+	// Operations that fail validation never reach the database. In an atomic
+	// batch one invalid operation means nothing is written at all.
+	var valid []int
+	for i := range req.Operations {
+		op := &req.Operations[i]
+		resp.Results[i] = model.BatchResult{Index: i, Action: op.Action, ID: op.ID}
+		if err := op.Validate(); err != nil {
+			resp.Results[i].Status = http.StatusUnprocessableEntity
+			resp.Results[i].Error = err.Error()
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	if req.Atomic && len(valid) < len(req.Operations) {
+		for _, i := range valid {
+			resp.Results[i].Status = http.StatusFailedDependency
+			resp.Results[i].Error = repository.ErrBatchRolledBack.Error()
+		}
+		h.writeBatch(w, resp)
+		return
+	}
+
+	ops := make([]model.BatchOperation, len(valid))
+	for j, i := range valid {
+		ops[j] = req.Operations[i]
+	}
+	errs, err := h.repo.Batch(r.Context(), ops, req.Atomic)
+	if err != nil {
+		h.logger.Error("product_batch_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to apply batch")
+		return
+	}
+
+	for j, i := range valid {
+		res := &resp.Results[i]
+		op := ops[j]
+		if errs[j] != nil {
+			res.Status, res.Error = productWriteErr(h.logger, op.Action, errs[j])
+			continue
+		}
+		switch op.Action {
+		case model.BatchCreate:
+			res.Status = http.StatusCreated
+			res.ID = op.Product.ID
+			res.Product = op.Product
+		case model.BatchUpdate:
+			res.Status = http.StatusOK
+			res.Product = op.Product
+		case model.BatchDelete:
+			res.Status = http.StatusOK
+		}
+	}
+	h.writeBatch(w, resp)
+}
+
+func (h *ProductHandler) writeBatch(w http.ResponseWriter, resp model.BatchResponse) {
+	for _, res := range resp.Results {
+		if res.Error == "" {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+	// A failed atomic batch reports every operation as failed, so anything
+	// that succeeded was committed.
+	resp.Committed = resp.Succeeded > 0
+	status := http.StatusOK
+	if resp.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	jsonOK(w, status, resp)
+}
+
+// productWriteErr maps the error of one create, update or delete that is part
+// of a larger request to the status and message the matching single-product
+// endpoint would have answered with.
+func productWriteErr(logger *slog.Logger, action model.BatchAction, err error) (int, string) {
+	switch {
+	case errors.Is(err, repository.ErrBatchRolledBack):
+		return http.StatusFailedDependency, err.Error()
+	case errors.Is(err, repository.ErrProductNotFound) && action == model.BatchCreate:
+		return http.StatusUnprocessableEntity, "parent product not found"
+	case errors.Is(err, repository.ErrProductNotFound):
+		return http.StatusNotFound, "product not found"
+	case errors.Is(err, repository.ErrInvalidTransition) && action == model.BatchCreate:
+		return http.StatusUnprocessableEntity, "new products must start as draft or active"
+	case errors.Is(err, repository.ErrInvalidTransition):
+		return http.StatusConflict, "product cannot move to that status"
+	case errors.Is(err, repository.ErrInvalidParent):
+		return http.StatusUnprocessableEntity, "variants can only belong to a top-level product"
+	case errors.Is(err, repository.ErrSKUTaken):
+		return http.StatusConflict, "sku already in use"
+	case errors.Is(err, repository.ErrCategoryNotFound):
+		return http.StatusUnprocessableEntity, "category not found"
+	case errors.Is(err, repository.ErrInvalidAttributes):
+		return http.StatusUnprocessableEntity, err.Error()
+	case errors.Is(err, repository.ErrProductInUse):
+		return http.StatusConflict, "product has variants or is referenced by orders, purchase orders or bundles and cannot be deleted"
+	default:
+		logger.Error("product_write_failed",
+			slog.String("action", string(action)),
+			slog.String("error", err.Error()),
+		)
+		return http.StatusInternalServerError, fmt.Sprintf("failed to %s product", action)
+	}
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+func (h *ProductHandler) GetBundle(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	bundle, err := h.repo.GetBundle(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrProductNotFound) {
+			jsonErr(w, http.StatusNotFound, "product not found")
+			return
+		}
+		h.logger.Error("get_bundle_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve bundle components")
+		return
+	}
+
+	jsonOK(w, http.StatusOK, bundle)
+}
+
+func (h *ProductHandler) SetBundle(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var b model.Bundle
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	b.ProductID = id
+
+	if err := b.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.repo.SetBundle(r.Context(), &b); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			jsonErr(w, http.StatusNotFound, "product not found")
+		case errors.Is(err, repository.ErrBundleStock):
+			jsonErr(w, http.StatusConflict, "product holds stock; move it out before turning the product into a bundle")
+		case errors.Is(err, repository.ErrInvalidComponent):
+			jsonErr(w, http.StatusUnprocessableEntity, "components must be existing products that are not bundles, and bundles cannot be components")
+		default:
+			h.logger.Error("set_bundle_failed", slog.String("error", err.Error()))
+			jsonErr(w, http.StatusInternalServerError, "failed to update bundle components")
+		}
+		return
+	}
+
+	bundle, err := h.repo.GetBundle(r.Context(), id)
+	if err != nil {
+		jsonOK(w, http.StatusOK, b)
+		return
+	}
+	jsonOK(w, http.StatusOK, bundle)
+}
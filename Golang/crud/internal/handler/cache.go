@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-sql/internal/model"
+)
+
+// validator identifies one version of a resource for conditional requests.
+type validator struct {
+	tag      string // opaque version, without quotes
+	weak     bool
+	modified time.Time // zero when the resource has no single modification time
+}
+
+// etag returns the entity tag for the representation named by variant, so
+// that e.g. the JSON and XML forms of a product never share a tag.
+func (v validator) etag(variant string) string {
+	tag := v.tag
+	if variant != "" {
+		tag += "-" + variant
+	}
+	if v.weak {
+		return `W/"` + tag + `"`
+	}
+	return `"` + tag + `"`
+}
+
+// productValidator is a strong validator for one product, from its
+// modification time.
+func productValidator(p *model.Product) validator {
+	return validator{
+		tag:      "p" + strconv.FormatInt(p.ID, 10) + "-" + strconv.FormatInt(p.UpdatedAt.UnixMicro(), 36),
+		modified: p.UpdatedAt,
+	}
+}
+
+// listValidator is a weak validator for a page of products, from the IDs and
+// modification times of the products and variants on it and the total count,
+// which also changes when a product is deleted. It has no modification time
+// since a deletion leaves none behind.
+func listValidator(res *model.PaginatedResponse) validator {
+	stamps := []int64{int64(res.Total), int64(res.Page), int64(res.PageSize)}
+	for _, p := range res.Products {
+		stamps = append(stamps, p.ID, p.UpdatedAt.UnixMicro())
+		for _, v := range p.Variants {
+			stamps = append(stamps, v.ID, v.UpdatedAt.UnixMicro())
+		}
+	}
+	return hashValidator(stamps)
+}
+
+// hashValidator is a weak validator for any value, from a hash of its JSON.
+func hashValidator(v any) validator {
+	b, _ := json.Marshal(v)
+	sum := sha256.Sum256(b)
+	return validator{tag: hex.EncodeToString(sum[:12]), weak: true}
+}
+
+// notModified sets the validators on the response and reports whether the
+// request's If-None-Match or, failing that, If-Modified-Since shows the
+// client's copy to be current. If so, 304 has been written.
+func notModified(w http.ResponseWriter, r *http.Request, etag string, modified time.Time) bool {
+	h := w.Header()
+	h.Set("Cache-Control", "no-cache")
+	h.Set("ETag", etag)
+	if !modified.IsZero() {
+		h.Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if !etagMatch(inm, etag) {
+			return false
+		}
+	} else {
+		ims := r.Header.Get("If-Modified-Since")
+		if ims == "" || modified.IsZero() {
+			return false
+		}
+		t, err := http.ParseTime(ims)
+		if err != nil || modified.Truncate(time.Second).After(t) {
+			return false
+		}
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatch does the weak comparison If-None-Match calls for.
+func etagMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+type CategoryHandler struct {
+	repo   repository.CategoryRepository
+	logger *slog.Logger
+}
+
+func NewCategoryHandler(repo repository.CategoryRepository, logger *slog.Logger) *CategoryHandler {
+	return &CategoryHandler{repo: repo, logger: logger}
+}
+
+func (h *CategoryHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/categories", h.ListCategories)
+	mux.HandleFunc("GET /api/categories/{id}", h.GetCategory)
+	mux.HandleFunc("POST /api/categories", h.CreateCategory)
+	mux.HandleFunc("PUT /api/categories/{id}", h.UpdateCategory)
+}
+
+func (h *CategoryHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.repo.List(r.Context())
+	if err != nil {
+		h.logger.Error("list_categories_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve categories")
+		return
+	}
+	jsonOK(w, http.StatusOK, categories)
+}
+
+func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid category ID")
+		return
+	}
+
+	category, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Error("get_category_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve category")
+		return
+	}
+	if category == nil {
+		jsonErr(w, http.StatusNotFound, "category not found")
+		return
+	}
+
+	jsonOK(w, http.StatusOK, category)
+}
+
+func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var c model.Category
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := c.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.repo.Create(r.Context(), &c); err != nil {
+		if errors.Is(err, repository.ErrCategoryNameTaken) {
+			jsonErr(w, http.StatusConflict, "category name already in use")
+			return
+		}
+		h.logger.Error("create_category_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to create category")
+		return
+	}
+
+	jsonOK(w, http.StatusCreated, c)
+}
+
+func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid category ID")
+		return
+	}
+
+	var c model.Category
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	c.ID = id
+
+	if err := c.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.repo.Update(r.Context(), &c); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrCategoryNotFound):
+			jsonErr(w, http.StatusNotFound, "category not found")
+		case errors.Is(err, repository.ErrCategoryNameTaken):
+			jsonErr(w, http.StatusConflict, "category name already in use")
+		default:
+			h.logger.Error("update_category_failed", slog.String("error", err.Error()))
+			jsonErr(w, http.StatusInternalServerError, "failed to update category")
+		}
+		return
+	}
+
+	updated, err := h.repo.GetByID(r.Context(), id)
+	if err != nil || updated == nil {
+		jsonOK(w, http.StatusOK, c)
+		return
+	}
+	jsonOK(w, http.StatusOK, updated)
+}
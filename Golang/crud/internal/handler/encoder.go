@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"golang-sql/internal/export"
+	"golang-sql/internal/model"
+)
+
+// An Encoder writes an API response body in one media type.
+type Encoder interface {
+	ContentType() string
+	Encode(w io.Writer, resp model.APIResponse) error
+}
+
+type registeredEncoder struct {
+	mediaType string
+	enc       Encoder
+}
+
+// encoders lists every media type responses can be negotiated into, in order
+// of preference when the client accepts several equally. JSON comes first so
+// that it is also the answer to */* and to requests without Accept.
+var encoders = []registeredEncoder{
+	{"application/json", jsonEncoder{}},
+	{"application/xml", xmlEncoder{"application/xml"}},
+	{"text/xml", xmlEncoder{"text/xml"}},
+	{"text/csv", csvEncoder{}},
+	{"application/msgpack", msgpackEncoder{}},
+	{"application/x-msgpack", msgpackEncoder{}},
+	{"application/vnd.msgpack", msgpackEncoder{}},
+}
+
+// RegisterEncoder makes responses available in mediaType, replacing any
+// encoder already registered for it. It must be called before the server
+// starts handling requests.
+func RegisterEncoder(mediaType string, enc Encoder) {
+	for i, e := range encoders {
+		if e.mediaType == mediaType {
+			encoders[i].enc = enc
+			return
+		}
+	}
+	encoders = append(encoders, registeredEncoder{mediaType, enc})
+}
+
+// negotiate picks the encoder for an Accept header, honouring q-values and
+// wildcards. Each media type takes the q-value of the most specific range
+// that covers it, so "application/*;q=0" refuses every application type that
+// is not named on its own. Ties go to the range listed first, then to the
+// order of encoders. It reports false when nothing acceptable is registered.
+func negotiate(accept string) (registeredEncoder, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return encoders[0], true
+	}
+
+	type mediaRange struct {
+		typ string
+		q   float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		typ, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{typ, q})
+	}
+
+	var (
+		best     registeredEncoder
+		bestQ    float64
+		bestRank int
+	)
+	for _, e := range encoders {
+		q, rank, specificity := 0.0, 0, -1
+		for i, r := range ranges {
+			if s := rangeSpecificity(r.typ, e.mediaType); s > specificity {
+				q, rank, specificity = r.q, i, s
+			}
+		}
+		if q > bestQ || q > 0 && q == bestQ && rank < bestRank {
+			best, bestQ, bestRank = e, q, rank
+		}
+	}
+	return best, bestQ > 0
+}
+
+// rangeSpecificity reports how closely the media range typ covers mediaType:
+// 2 when it names it, 1 for its type/*, 0 for */* and -1 when it does not
+// cover it at all.
+func rangeSpecificity(typ, mediaType string) int {
+	switch {
+	case typ == mediaType:
+		return 2
+	case strings.HasSuffix(typ, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(typ, "*")):
+		return 1
+	case typ == "*/*":
+		return 0
+	}
+	return -1
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) ContentType() string { return "application/json; charset=utf-8" }
+
+func (jsonEncoder) Encode(w io.Writer, resp model.APIResponse) error {
+	return json.NewEncoder(w).Encode(resp)
+}
+
+type msgpackEncoder struct{}
+
+func (msgpackEncoder) ContentType() string { return "application/msgpack" }
+
+// Encode uses the json struct tags, so field names match the JSON API.
+func (msgpackEncoder) Encode(w io.Writer, resp model.APIResponse) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	return enc.Encode(resp)
+}
+
+var errNotTabular = errors.New("response has no tabular form")
+
+type csvEncoder struct{}
+
+func (csvEncoder) ContentType() string { return "text/csv; charset=utf-8" }
+
+// Encode writes products with the columns of the product export, and errors
+// as a single "error" column. Other responses have no CSV form.
+func (csvEncoder) Encode(w io.Writer, resp model.APIResponse) error {
+	if !resp.Success {
+		_, err := io.WriteString(w, "error\n"+csvField(resp.Error)+"\n")
+		return err
+	}
+
+	var products []model.Product
+	switch data := resp.Data.(type) {
+	case *model.PaginatedResponse:
+		products = data.Products
+	case *model.Product:
+		products = []model.Product{*data}
+	case []model.Product:
+		products = data
+	default:
+		return errNotTabular
+	}
+
+	out, err := export.Formats["csv"].New(w)
+	if err != nil {
+		return err
+	}
+	for i := range products {
+		if err := out.Write(&products[i]); err != nil {
+			return err
+		}
+	}
+	return out.Close()
+}
+
+func csvField(s string) string {
+	if strings.ContainsAny(s, "\",\r\n") {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return s
+}
+
+// xmlEncoder answers in whichever XML media type was negotiated.
+type xmlEncoder struct {
+	mediaType string
+}
+
+func (e xmlEncoder) ContentType() string { return e.mediaType + "; charset=utf-8" }
+
+// Encode mirrors the JSON form of the response: objects become elements named
+// after their keys, array items become <item> elements and the whole response
+// sits in a <response> element. Keys that are not valid element names, such
+// as free-form option names, are written as <entry key="...">.
+func (xmlEncoder) Encode(w io.Writer, resp model.APIResponse) error {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	if err := writeXMLValue(bw, dec, "response"); err != nil {
+		return err
+	}
+	bw.WriteString("\n")
+	return bw.Flush()
+}
+
+var xmlName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+func writeXMLValue(w *bufio.Writer, dec *json.Decoder, name string) error {
+	open, end := "<"+name+">", "</"+name+">"
+	if !xmlName.MatchString(name) || strings.HasPrefix(strings.ToLower(name), "xml") {
+		var key bytes.Buffer
+		xml.EscapeText(&key, []byte(name))
+		open, end = `<entry key="`+key.String()+`">`, "</entry>"
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	w.WriteString(open)
+	switch t := tok.(type) {
+	case json.Delim:
+		for dec.More() {
+			child := "item"
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child = key.(string)
+			}
+			if err := writeXMLValue(w, dec, child); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	case string:
+		xml.EscapeText(w, []byte(t))
+	case json.Number:
+		w.WriteString(t.String())
+	case bool:
+		w.WriteString(strconv.FormatBool(t))
+	}
+	_, err = w.WriteString(end)
+	return err
+}
@@ -0,0 +1,51 @@
+package handler
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string // "" when nothing is acceptable
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"application/json", "application/json"},
+		{"application/xml", "application/xml"},
+		{"text/csv, application/json;q=0.5", "text/csv"},
+		{"application/json;q=0.2, application/msgpack;q=0.9", "application/msgpack"},
+		{"text/*", "text/xml"},
+		{"application/pdf, */*;q=0.1", "application/json"},
+		{"application/json;q=0, */*", "application/xml"},
+		{"application/json;q=0, application/xml;q=0, */*", "text/xml"},
+		{"application/*;q=0, */*", "text/xml"},
+		{"application/*;q=0, application/msgpack", "application/msgpack"},
+		{"*/*, application/json;q=0.1", "application/xml"},
+		{"text/*;q=0.5, text/csv", "text/csv"},
+		{"image/png", ""},
+		{"application/json;q=0", ""},
+		{"not a media type, text/csv", "text/csv"},
+	}
+	for _, tt := range tests {
+		e, ok := negotiate(tt.accept)
+		switch {
+		case tt.want == "" && ok:
+			t.Errorf("Accept %q: got %s, want nothing acceptable", tt.accept, e.mediaType)
+		case tt.want != "" && !ok:
+			t.Errorf("Accept %q: got nothing acceptable, want %s", tt.accept, tt.want)
+		case ok && e.mediaType != tt.want:
+			t.Errorf("Accept %q: got %s, want %s", tt.accept, e.mediaType, tt.want)
+		}
+	}
+}
+
+func TestXMLContentType(t *testing.T) {
+	for _, mediaType := range []string{"application/xml", "text/xml"} {
+		e, ok := negotiate(mediaType)
+		if !ok {
+			t.Fatalf("%s not acceptable", mediaType)
+		}
+		if got, want := e.enc.ContentType(), mediaType+"; charset=utf-8"; got != want {
+			t.Errorf("Accept %s: Content-Type %q, want %q", mediaType, got, want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang-sql/internal/config"
+	"golang-sql/internal/stream"
+)
+
+type EventHandler struct {
+	hub    *stream.Hub
+	cfg    config.StreamConfig
+	logger *slog.Logger
+}
+
+func NewEventHandler(hub *stream.Hub, cfg config.StreamConfig, logger *slog.Logger) *EventHandler {
+	return &EventHandler{hub: hub, cfg: cfg, logger: logger}
+}
+
+func (h *EventHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/events", h.Stream)
+}
+
+// Stream sends product change events and catalog stats as Server-Sent
+// Events until the client goes away. A client reconnecting with
+// Last-Event-ID first receives the events it missed; the ready message
+// tells it whether it did, or must reload instead.
+func (h *EventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	var after int64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		var err error
+		if after, err = strconv.ParseInt(v, 10, 64); err != nil || after < 0 {
+			jsonErr(w, http.StatusBadRequest, "invalid Last-Event-ID")
+			return
+		}
+	}
+
+	messages, unsubscribe := h.hub.Subscribe()
+	defer unsubscribe()
+
+	backlog, resume, resumed, err := h.hub.Backlog(r.Context(), after)
+	if err != nil {
+		h.logger.Error("event_stream_backlog_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to open event stream")
+		return
+	}
+	ready, _ := json.Marshal(map[string]bool{"resumed": resumed})
+	stats, err := h.hub.Stats(r.Context())
+	if err != nil {
+		h.logger.Error("event_stream_stats_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to open event stream")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// The server's write timeout would end the stream; instead each write
+	// gets a heartbeat's worth of time, so a client that stops reading is
+	// dropped.
+	rc := http.NewResponseController(w)
+	send := func(m stream.Message) bool {
+		_ = rc.SetWriteDeadline(time.Now().Add(h.cfg.Heartbeat))
+		if _, err := m.WriteTo(w); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	// Events may be broadcast out of ID order, so broadcasts are skipped by
+	// which events the backlog held rather than by ID.
+	replayed := make(map[int64]bool, len(backlog))
+	for _, m := range backlog {
+		if !send(m) {
+			return
+		}
+		replayed[m.EventID] = true
+	}
+	if !send(stream.Message{ID: resume, Event: stream.ReadyEvent, Data: ready}) || !send(stats) {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.cfg.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case m, ok := <-messages:
+			if !ok {
+				return
+			}
+			if m.EventID != 0 && replayed[m.EventID] {
+				continue
+			}
+			if !send(m) {
+				return
+			}
+		case <-heartbeat.C:
+			_ = rc.SetWriteDeadline(time.Now().Add(h.cfg.Heartbeat))
+			if _, err := w.Write([]byte(": ping\n\n")); err != nil || rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
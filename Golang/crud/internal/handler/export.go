@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"golang-sql/internal/config"
+	"golang-sql/internal/export"
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+type ExportHandler struct {
+	repo   repository.ProductRepository
+	cfg    config.ExportConfig
+	logger *slog.Logger
+}
+
+func NewExportHandler(repo repository.ProductRepository, cfg config.ExportConfig, logger *slog.Logger) *ExportHandler {
+	return &ExportHandler{repo: repo, cfg: cfg, logger: logger}
+}
+
+func (h *ExportHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/export/products", h.ExportProducts)
+}
+
+// ExportProducts streams every product matching the ListProducts filters as
+// a csv (the default), ndjson or xlsx download.
+func (h *ExportHandler) ExportProducts(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = "csv"
+	}
+	format, ok := export.Formats[name]
+	if !ok {
+		jsonErr(w, http.StatusBadRequest, "format must be one of csv, ndjson, xlsx")
+		return
+	}
+	f, err := parseProductFilter(r.URL.Query())
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// A full dump can outlast the request timeout every other endpoint runs
+	// under, so the export gets its own deadline. A client that goes away is
+	// still noticed: the next write fails and ends the export.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), h.cfg.Timeout)
+	defer cancel()
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(h.cfg.Timeout))
+
+	// The writer is only created with the first row, so an error before then
+	// can still be answered with a JSON error.
+	var out export.Writer
+	start := func() (err error) {
+		w.Header().Set("Content-Type", format.ContentType)
+		w.Header().Set("Content-Disposition",
+			`attachment; filename="products-`+time.Now().Format("20060102")+format.Extension+`"`)
+		out, err = format.New(w)
+		return err
+	}
+	err = h.repo.Export(ctx, f, func(p *model.Product) error {
+		if out == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return out.Write(p)
+	})
+	if err == nil && out == nil {
+		err = start()
+	}
+	if err != nil {
+		h.logger.Error("export_products_failed", slog.String("format", name), slog.String("error", err.Error()))
+		if out == nil {
+			jsonErr(w, http.StatusInternalServerError, "failed to export products")
+		}
+		return
+	}
+	if err := out.Close(); err != nil {
+		h.logger.Error("export_products_failed", slog.String("format", name), slog.String("error", err.Error()))
+	}
+}
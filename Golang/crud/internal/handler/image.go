@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"golang-sql/internal/config"
+	"golang-sql/internal/media"
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+type ImageHandler struct {
+	repo   repository.ImageRepository
+	store  media.Store
+	cfg    config.MediaConfig
+	logger *slog.Logger
+}
+
+func NewImageHandler(repo repository.ImageRepository, store media.Store, cfg config.MediaConfig, logger *slog.Logger) *ImageHandler {
+	return &ImageHandler{repo: repo, store: store, cfg: cfg, logger: logger}
+}
+
+func (h *ImageHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/products/{id}/images", h.ListImages)
+	mux.HandleFunc("POST /api/products/{id}/images", h.UploadImage)
+	mux.HandleFunc("DELETE /api/products/{id}/images/{imageId}", h.DeleteImage)
+	mux.HandleFunc("GET "+model.MediaPath+"{key...}", h.ServeMedia)
+}
+
+func (h *ImageHandler) ListImages(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	images, err := h.repo.List(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrProductNotFound) {
+			jsonErr(w, http.StatusNotFound, "product not found")
+			return
+		}
+		h.logger.Error("list_images_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve images")
+		return
+	}
+
+	jsonOK(w, http.StatusOK, images)
+}
+
+// UploadImage accepts a multipart/form-data upload with the file in the
+// "image" field. The type is sniffed from the content, and the original and a
+// thumbnail are stored before the image is recorded against the product.
+func (h *ImageHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	// Leave room for the multipart framing around the file itself.
+	r.Body = http.MaxBytesReader(w, r.Body, h.cfg.MaxUploadBytes+64<<10)
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			jsonErr(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("image must be %d bytes or less", h.cfg.MaxUploadBytes))
+			return
+		}
+		jsonErr(w, http.StatusBadRequest, `multipart field "image" is required`)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.cfg.MaxUploadBytes+1))
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "failed to read upload")
+		return
+	}
+	if int64(len(data)) > h.cfg.MaxUploadBytes {
+		jsonErr(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("image must be %d bytes or less", h.cfg.MaxUploadBytes))
+		return
+	}
+
+	processed, err := media.Process(data, h.cfg.ThumbnailSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, media.ErrUnsupportedType):
+			jsonErr(w, http.StatusUnsupportedMediaType, "image must be JPEG, PNG or GIF")
+		case errors.Is(err, media.ErrInvalidImage):
+			jsonErr(w, http.StatusUnprocessableEntity, "image could not be decoded")
+		default:
+			h.logger.Error("process_image_failed", slog.String("error", err.Error()))
+			jsonErr(w, http.StatusInternalServerError, "failed to process image")
+		}
+		return
+	}
+
+	token := make([]byte, 12)
+	rand.Read(token)
+	base := fmt.Sprintf("products/%d/%s", id, hex.EncodeToString(token))
+	img := model.ProductImage{
+		ProductID:    id,
+		StorageKey:   base + processed.Ext,
+		ThumbnailKey: base + "_thumb" + processed.ThumbnailExt,
+		ContentType:  processed.ContentType,
+		SizeBytes:    int64(len(data)),
+		Width:        processed.Width,
+		Height:       processed.Height,
+	}
+
+	if err := h.store.Put(r.Context(), img.StorageKey, bytes.NewReader(data)); err != nil {
+		h.logger.Error("store_image_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to store image")
+		return
+	}
+	if err := h.store.Put(r.Context(), img.ThumbnailKey, bytes.NewReader(processed.Thumbnail)); err != nil {
+		h.removeFiles(r, &img)
+		h.logger.Error("store_thumbnail_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to store image")
+		return
+	}
+
+	if err := h.repo.Add(r.Context(), &img); err != nil {
+		h.removeFiles(r, &img)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			jsonErr(w, http.StatusNotFound, "product not found")
+			return
+		}
+		h.logger.Error("add_image_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to save image")
+		return
+	}
+
+	jsonOK(w, http.StatusCreated, img)
+}
+
+func (h *ImageHandler) DeleteImage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+	imageID, err := strconv.ParseInt(r.PathValue("imageId"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid image ID")
+		return
+	}
+
+	img, err := h.repo.Delete(r.Context(), id, imageID)
+	if err != nil {
+		if errors.Is(err, repository.ErrImageNotFound) {
+			jsonErr(w, http.StatusNotFound, "image not found")
+			return
+		}
+		h.logger.Error("delete_image_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to delete image")
+		return
+	}
+	h.removeFiles(r, img)
+
+	jsonOK(w, http.StatusOK, map[string]string{"message": "image deleted"})
+}
+
+// ServeMedia streams a stored file. Keys are never reused, so responses can
+// be cached indefinitely.
+func (h *ImageHandler) ServeMedia(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	rc, err := h.store.Open(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, media.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.logger.Error("open_media_failed", slog.String("error", err.Error()))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer rc.Close()
+
+	if ct := mime.TypeByExtension(path.Ext(key)); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	if _, err := io.Copy(w, rc); err != nil {
+		h.logger.Warn("serve_media_interrupted", slog.String("key", key), slog.String("error", err.Error()))
+	}
+}
+
+func (h *ImageHandler) removeFiles(r *http.Request, img *model.ProductImage) {
+	for _, key := range []string{img.StorageKey, img.ThumbnailKey} {
+		if err := h.store.Delete(r.Context(), key); err != nil {
+			h.logger.Warn("delete_media_failed", slog.String("key", key), slog.String("error", err.Error()))
+		}
+	}
+}
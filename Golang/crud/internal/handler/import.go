@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang-sql/internal/config"
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+var errNoCSV = errors.New(`upload the csv as text/csv or in the multipart field "file"`)
+
+type ImportHandler struct {
+	repo   repository.ProductRepository
+	cfg    config.ImportConfig
+	logger *slog.Logger
+}
+
+func NewImportHandler(repo repository.ProductRepository, cfg config.ImportConfig, logger *slog.Logger) *ImportHandler {
+	return &ImportHandler{repo: repo, cfg: cfg, logger: logger}
+}
+
+func (h *ImportHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/import/products", h.ImportProducts)
+}
+
+// ImportProducts reads a CSV of products row by row, creating products it
+// cannot match and updating those it can, and answers with a report on every
+// row. The file is either the request body (text/csv) or the "file" field of
+// a multipart/form-data upload. With dry_run=true rows are checked and
+// matched but nothing is written.
+func (h *ImportHandler) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	var dryRun bool
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			jsonErr(w, http.StatusBadRequest, "dry_run must be true or false")
+			return
+		}
+	}
+
+	// A large file takes longer to apply than the request timeout every other
+	// endpoint runs under, so the import gets its own deadline and is not cut
+	// off half applied when the client goes away.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), h.cfg.Timeout)
+	defer cancel()
+	r = r.WithContext(ctx)
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Now().Add(h.cfg.Timeout))
+	_ = rc.SetWriteDeadline(time.Now().Add(h.cfg.Timeout))
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.cfg.MaxUploadBytes)
+	body, err := csvBody(r)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			jsonErr(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("upload must be %d bytes or less", h.cfg.MaxUploadBytes))
+		case errors.Is(err, errNoCSV):
+			jsonErr(w, http.StatusUnsupportedMediaType, err.Error())
+		default:
+			jsonErr(w, http.StatusBadRequest, "invalid multipart upload")
+		}
+		return
+	}
+
+	cr := csv.NewReader(body)
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			jsonErr(w, http.StatusUnprocessableEntity, "csv has no header row")
+			return
+		}
+		jsonErr(w, http.StatusBadRequest, "invalid csv header")
+		return
+	}
+	cols, err := model.ParseImportHeader(header)
+	if err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	report := model.ImportReport{DryRun: dryRun, Results: []model.ImportRowResult{}}
+	// Products a dry run would have created, by SKU, so that later rows for
+	// the same SKU are reported as the updates they would become.
+	pending := make(map[string]*model.Product)
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			report.Rows++
+			report.Failed++
+			report.Results = append(report.Results, model.ImportRowResult{Line: parseErr.StartLine, Error: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				report.Error = fmt.Sprintf("upload exceeds %d bytes; remaining rows were not imported", h.cfg.MaxUploadBytes)
+			} else {
+				report.Error = "reading csv: " + err.Error()
+			}
+			break
+		}
+		if report.Rows == h.cfg.MaxRows {
+			report.Error = fmt.Sprintf("csv may hold at most %d rows; remaining rows were not imported", h.cfg.MaxRows)
+			break
+		}
+		if r.Context().Err() != nil {
+			report.Error = "import timed out; remaining rows were not imported"
+			break
+		}
+
+		report.Rows++
+		res := h.importRow(r, cols, record, dryRun, pending)
+		res.Line, _ = cr.FieldPos(0)
+		switch {
+		case res.Error != "":
+			report.Failed++
+		case res.Action == model.BatchCreate:
+			report.Created++
+		default:
+			report.Updated++
+		}
+		report.Results = append(report.Results, res)
+	}
+
+	jsonOK(w, http.StatusOK, report)
+}
+
+func (h *ImportHandler) importRow(r *http.Request, cols model.ImportHeader, record []string, dryRun bool, pending map[string]*model.Product) model.ImportRowResult {
+	id, sku, err := cols.Key(record)
+	if err != nil {
+		return model.ImportRowResult{SKU: sku, Error: err.Error()}
+	}
+	res := model.ImportRowResult{ID: id, SKU: sku}
+
+	var existing *model.Product
+	switch {
+	case id != 0:
+		// The row is merged into what is read here and written back, so a
+		// lagging replica must not supply it.
+		existing, err = h.repo.GetByID(repository.ReadPrimary(r.Context()), id)
+	case pending[sku] != nil:
+		existing = pending[sku]
+	default:
+		existing, err = h.repo.GetBySKU(r.Context(), sku)
+	}
+	if err != nil {
+		h.logger.Error("import_lookup_failed", slog.String("error", err.Error()))
+		res.Error = "failed to look up product"
+		return res
+	}
+	if existing == nil && id != 0 {
+		res.Error = "product not found"
+		return res
+	}
+
+	p := model.Product{ReorderPoint: model.DefaultReorderPoint}
+	res.Action = model.BatchCreate
+	if existing != nil {
+		p = *existing
+		p.Images, p.Variants = nil, nil
+		res.Action = model.BatchUpdate
+	}
+	if err := cols.Apply(record, &p); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if err := p.Validate(); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.SKU = p.SKU
+
+	if dryRun {
+		if res.Action == model.BatchCreate && p.SKU != "" {
+			pending[p.SKU] = &p
+		}
+		return res
+	}
+
+	if res.Action == model.BatchCreate {
+		err = h.repo.Create(r.Context(), &p)
+	} else {
+		err = h.repo.Update(r.Context(), &p)
+	}
+	if err != nil {
+		_, res.Error = productWriteErr(h.logger, res.Action, err)
+		return res
+	}
+	res.ID = p.ID
+	return res
+}
+
+// csvBody returns the CSV stream of an import request without buffering the
+// upload.
+func csvBody(r *http.Request) (io.Reader, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		return r.Body, nil
+	case "multipart/form-data":
+		mr, err := r.MultipartReader()
+		if err != nil {
+			return nil, err
+		}
+		for {
+			part, err := mr.NextPart()
+			if errors.Is(err, io.EOF) {
+				return nil, errNoCSV
+			}
+			if err != nil {
+				return nil, err
+			}
+			if part.FormName() == "file" {
+				return part, nil
+			}
+		}
+	default:
+		return nil, errNoCSV
+	}
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+type OrderHandler struct {
+	repo   repository.OrderRepository
+	logger *slog.Logger
+}
+
+func NewOrderHandler(repo repository.OrderRepository, logger *slog.Logger) *OrderHandler {
+	return &OrderHandler{repo: repo, logger: logger}
+}
+
+func (h *OrderHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/orders", h.ListOrders)
+	mux.HandleFunc("GET /api/orders/{id}", h.GetOrder)
+	mux.HandleFunc("POST /api/orders", h.CreateOrder)
+	mux.HandleFunc("POST /api/orders/{id}/pay", h.PayOrder)
+	mux.HandleFunc("POST /api/orders/{id}/fulfill", h.FulfillOrder)
+	mux.HandleFunc("POST /api/orders/{id}/cancel", h.CancelOrder)
+}
+
+func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	status := model.OrderStatus(r.URL.Query().Get("status"))
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	orders, err := h.repo.List(r.Context(), status, page, pageSize)
+	if err != nil {
+		h.logger.Error("list_orders_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve orders")
+		return
+	}
+	jsonOK(w, http.StatusOK, orders)
+}
+
+func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid order ID")
+		return
+	}
+
+	order, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Error("get_order_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve order")
+		return
+	}
+	if order == nil {
+		jsonErr(w, http.StatusNotFound, "order not found")
+		return
+	}
+
+	jsonOK(w, http.StatusOK, order)
+}
+
+func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	var o model.Order
+	if err := json.NewDecoder(r.Body).Decode(&o); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := o.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.repo.Create(r.Context(), &o); err != nil {
+		h.orderErr(w, "create_order_failed", "failed to create order", err)
+		return
+	}
+
+	jsonOK(w, http.StatusCreated, o)
+}
+
+func (h *OrderHandler) PayOrder(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, model.OrderPaid)
+}
+
+func (h *OrderHandler) FulfillOrder(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, model.OrderFulfilled)
+}
+
+func (h *OrderHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, model.OrderCancelled)
+}
+
+func (h *OrderHandler) transition(w http.ResponseWriter, r *http.Request, to model.OrderStatus) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid order ID")
+		return
+	}
+
+	var c model.OrderStatusChange
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := c.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.repo.Transition(r.Context(), id, to, c); err != nil {
+		h.orderErr(w, "transition_order_failed", "failed to update order", err)
+		return
+	}
+
+	order, err := h.repo.GetByID(r.Context(), id)
+	if err != nil || order == nil {
+		jsonOK(w, http.StatusOK, map[string]string{"message": "order updated"})
+		return
+	}
+	jsonOK(w, http.StatusOK, order)
+}
+
+func (h *OrderHandler) orderErr(w http.ResponseWriter, event, msg string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrOrderNotFound):
+		jsonErr(w, http.StatusNotFound, "order not found")
+	case errors.Is(err, repository.ErrProductNotFound):
+		jsonErr(w, http.StatusUnprocessableEntity, "product not found")
+	case errors.Is(err, repository.ErrWarehouseNotFound):
+		jsonErr(w, http.StatusUnprocessableEntity, "warehouse not found")
+	case errors.Is(err, repository.ErrInsufficientStock):
+		jsonErr(w, http.StatusConflict, "insufficient stock")
+	case errors.Is(err, repository.ErrProductNotForSale):
+		jsonErr(w, http.StatusConflict, "product is not for sale")
+	case errors.Is(err, repository.ErrInvalidTransition):
+		jsonErr(w, http.StatusConflict, "order cannot move to that status")
+	default:
+		h.logger.Error(event, slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, msg)
+	}
+}
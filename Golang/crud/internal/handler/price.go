@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+type PriceHandler struct {
+	repo   repository.PriceRepository
+	logger *slog.Logger
+}
+
+func NewPriceHandler(repo repository.PriceRepository, logger *slog.Logger) *PriceHandler {
+	return &PriceHandler{repo: repo, logger: logger}
+}
+
+func (h *PriceHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/products/{id}/prices", h.GetPrices)
+	mux.HandleFunc("POST /api/products/{id}/prices", h.SchedulePrice)
+	mux.HandleFunc("DELETE /api/products/{id}/prices/{scheduleId}", h.CancelScheduledPrice)
+}
+
+func (h *PriceHandler) GetPrices(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	overview, err := h.repo.Overview(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrProductNotFound) {
+			jsonErr(w, http.StatusNotFound, "product not found")
+			return
+		}
+		h.logger.Error("get_prices_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve prices")
+		return
+	}
+
+	jsonOK(w, http.StatusOK, overview)
+}
+
+func (h *PriceHandler) SchedulePrice(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var sp model.ScheduledPrice
+	if err := json.NewDecoder(r.Body).Decode(&sp); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	sp.ProductID = id
+
+	if err := sp.Validate(time.Now()); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.repo.Schedule(r.Context(), &sp); err != nil {
+		if errors.Is(err, repository.ErrProductNotFound) {
+			jsonErr(w, http.StatusNotFound, "product not found")
+			return
+		}
+		h.logger.Error("schedule_price_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to schedule price")
+		return
+	}
+
+	jsonOK(w, http.StatusCreated, sp)
+}
+
+func (h *PriceHandler) CancelScheduledPrice(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+	scheduleID, err := strconv.ParseInt(r.PathValue("scheduleId"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid schedule ID")
+		return
+	}
+
+	if err := h.repo.CancelScheduled(r.Context(), id, scheduleID); err != nil {
+		if errors.Is(err, repository.ErrScheduledPriceNotFound) {
+			jsonErr(w, http.StatusNotFound, "pending scheduled price not found")
+			return
+		}
+		h.logger.Error("cancel_scheduled_price_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to cancel scheduled price")
+		return
+	}
+
+	jsonOK(w, http.StatusOK, map[string]string{"message": "scheduled price cancelled"})
+}
@@ -2,48 +2,52 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"html/template"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
 
+	"golang-sql/internal/config"
 	"golang-sql/internal/model"
 	"golang-sql/internal/repository"
 )
 
 type ProductHandler struct {
 	repo   repository.ProductRepository
+	batch  config.BatchConfig
 	logger *slog.Logger
 	tmpl   *template.Template
 }
 
-func NewProductHandler(repo repository.ProductRepository, logger *slog.Logger, tmpl *template.Template) *ProductHandler {
-	return &ProductHandler{repo: repo, logger: logger, tmpl: tmpl}
+func NewProductHandler(repo repository.ProductRepository, batch config.BatchConfig, logger *slog.Logger, tmpl *template.Template) *ProductHandler {
+	return &ProductHandler{repo: repo, batch: batch, logger: logger, tmpl: tmpl}
 }
 
 func (h *ProductHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /{$}", h.ServeIndex)
 	mux.HandleFunc("GET /api/products", h.ListProducts)
+	mux.HandleFunc("GET /api/products/reorder", h.ListReorder)
 	mux.HandleFunc("GET /api/products/{id}", h.GetProduct)
 	mux.HandleFunc("POST /api/products", h.CreateProduct)
+	mux.HandleFunc("POST /api/products:batch", h.Batch)
 	mux.HandleFunc("PUT /api/products/{id}", h.UpdateProduct)
 	mux.HandleFunc("DELETE /api/products/{id}", h.DeleteProduct)
+	mux.HandleFunc("GET /api/products/{id}/variants", h.ListVariants)
+	mux.HandleFunc("POST /api/products/{id}/variants", h.CreateVariant)
+	mux.HandleFunc("GET /api/products/{id}/components", h.GetBundle)
+	mux.HandleFunc("PUT /api/products/{id}/components", h.SetBundle)
+	mux.HandleFunc("POST /api/products/{id}/activate", h.ActivateProduct)
+	mux.HandleFunc("POST /api/products/{id}/discontinue", h.DiscontinueProduct)
+	mux.HandleFunc("POST /api/products/{id}/archive", h.ArchiveProduct)
+	mux.HandleFunc("GET /api/products/{id}/stock-movements", h.ListStockMovements)
+	mux.HandleFunc("POST /api/products/{id}/stock-movements", h.CreateStockMovement)
+	mux.HandleFunc("POST /api/products/{id}/stock:adjust", h.AdjustStock)
 	mux.HandleFunc("GET /api/stats", h.GetStats)
 	mux.HandleFunc("GET /health", h.HealthCheck)
 }
 
-func (h *ProductHandler) jsonOK(w http.ResponseWriter, status int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(model.APIResponse{Success: true, Data: data})
-}
-
-func (h *ProductHandler) jsonErr(w http.ResponseWriter, status int, msg string) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(model.APIResponse{Success: false, Error: msg})
-}
-
 func (h *ProductHandler) ServeIndex(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	if err := h.tmpl.Execute(w, nil); err != nil {
@@ -53,7 +57,6 @@ func (h *ProductHandler) ServeIndex(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
-	search := r.URL.Query().Get("search")
 	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
 	pageSize, _ := strconv.Atoi(r.URL.Query().Get("limit"))
 
@@ -64,119 +67,251 @@ func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 		pageSize = 10
 	}
 
-	result, err := h.repo.List(r.Context(), search, page, pageSize)
+	f, err := parseProductFilter(r.URL.Query())
+	if err != nil {
+		respondErr(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.repo.List(r.Context(), f, page, pageSize)
 	if err != nil {
 		h.logger.Error("list_products_failed", slog.String("error", err.Error()))
-		h.jsonErr(w, http.StatusInternalServerError, "failed to retrieve products")
+		respondErr(w, r, http.StatusInternalServerError, "failed to retrieve products")
 		return
 	}
 
-	h.jsonOK(w, http.StatusOK, result)
+	respondCached(w, r, listValidator(result), result)
+}
+
+// parseProductFilter reads the search and filter parameters shared by the
+// product listing and export.
+func parseProductFilter(q url.Values) (model.ProductFilter, error) {
+	// Only active products are listed unless a status (or "all") is asked for.
+	f := model.ProductFilter{Search: q.Get("search"), Status: model.ProductActive}
+	switch s := q.Get("status"); s {
+	case "":
+	case "all":
+		f.Status = ""
+	default:
+		f.Status = model.ProductStatus(s)
+		if !f.Status.Valid() {
+			return f, errors.New("status must be one of draft, active, discontinued, archived, all")
+		}
+	}
+	switch q.Get("view") {
+	case "", "flat":
+	case "grouped":
+		f.Grouped = true
+	default:
+		return f, errors.New("view must be flat or grouped")
+	}
+	if c := q.Get("category"); c != "" {
+		id, err := strconv.ParseInt(c, 10, 64)
+		if err != nil || id <= 0 {
+			return f, errors.New("invalid category ID")
+		}
+		f.CategoryID = id
+	}
+	for _, a := range q["attr"] {
+		af, err := model.ParseAttributeFilter(a)
+		if err != nil {
+			return f, err
+		}
+		f.Attributes = append(f.Attributes, af)
+	}
+	return f, nil
 }
 
 func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		h.jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		respondErr(w, r, http.StatusBadRequest, "invalid product ID")
 		return
 	}
 
 	product, err := h.repo.GetByID(r.Context(), id)
 	if err != nil {
 		h.logger.Error("get_product_failed", slog.String("error", err.Error()))
-		h.jsonErr(w, http.StatusInternalServerError, "failed to retrieve product")
+		respondErr(w, r, http.StatusInternalServerError, "failed to retrieve product")
 		return
 	}
 	if product == nil {
-		h.jsonErr(w, http.StatusNotFound, "product not found")
+		respondErr(w, r, http.StatusNotFound, "product not found")
 		return
 	}
 
-	h.jsonOK(w, http.StatusOK, product)
+	respondCached(w, r, productValidator(product), product)
 }
 
 func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	var p model.Product
 	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
-		h.jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
 		return
 	}
 
 	if err := p.Validate(); err != nil {
-		h.jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
 		return
 	}
 
 	if err := h.repo.Create(r.Context(), &p); err != nil {
-		h.logger.Error("create_product_failed", slog.String("error", err.Error()))
-		h.jsonErr(w, http.StatusInternalServerError, "failed to create product")
+		switch {
+		case errors.Is(err, repository.ErrInvalidTransition):
+			jsonErr(w, http.StatusUnprocessableEntity, "new products must start as draft or active")
+		case errors.Is(err, repository.ErrProductNotFound):
+			jsonErr(w, http.StatusUnprocessableEntity, "parent product not found")
+		case errors.Is(err, repository.ErrInvalidParent):
+			jsonErr(w, http.StatusUnprocessableEntity, "variants can only belong to a top-level product")
+		case errors.Is(err, repository.ErrSKUTaken):
+			jsonErr(w, http.StatusConflict, "sku already in use")
+		case errors.Is(err, repository.ErrCategoryNotFound):
+			jsonErr(w, http.StatusUnprocessableEntity, "category not found")
+		case errors.Is(err, repository.ErrInvalidAttributes):
+			jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			h.logger.Error("create_product_failed", slog.String("error", err.Error()))
+			jsonErr(w, http.StatusInternalServerError, "failed to create product")
+		}
 		return
 	}
 
-	h.jsonOK(w, http.StatusCreated, p)
+	jsonOK(w, http.StatusCreated, p)
 }
 
 func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		h.jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
 		return
 	}
 
 	var p model.Product
 	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
-		h.jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
 		return
 	}
 	p.ID = id
 
 	if err := p.Validate(); err != nil {
-		h.jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
 		return
 	}
 
 	if err := h.repo.Update(r.Context(), &p); err != nil {
-		h.logger.Error("update_product_failed", slog.String("error", err.Error()))
-		h.jsonErr(w, http.StatusInternalServerError, "failed to update product")
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			jsonErr(w, http.StatusNotFound, "product not found")
+		case errors.Is(err, repository.ErrInvalidTransition):
+			jsonErr(w, http.StatusConflict, "product cannot move to that status")
+		case errors.Is(err, repository.ErrSKUTaken):
+			jsonErr(w, http.StatusConflict, "sku already in use")
+		case errors.Is(err, repository.ErrCategoryNotFound):
+			jsonErr(w, http.StatusUnprocessableEntity, "category not found")
+		case errors.Is(err, repository.ErrInvalidAttributes):
+			jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			h.logger.Error("update_product_failed", slog.String("error", err.Error()))
+			jsonErr(w, http.StatusInternalServerError, "failed to update product")
+		}
 		return
 	}
 
-	updated, err := h.repo.GetByID(r.Context(), id)
+	updated, err := h.repo.GetByID(repository.ReadPrimary(r.Context()), id)
 	if err != nil || updated == nil {
-		h.jsonOK(w, http.StatusOK, p)
+		jsonOK(w, http.StatusOK, p)
 		return
 	}
-	h.jsonOK(w, http.StatusOK, updated)
+	jsonOK(w, http.StatusOK, updated)
 }
 
 func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		h.jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
 		return
 	}
 
 	if err := h.repo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrProductNotFound) {
+			jsonErr(w, http.StatusNotFound, "product not found")
+			return
+		}
+		if errors.Is(err, repository.ErrProductInUse) {
+			jsonErr(w, http.StatusConflict, "product has variants or is referenced by orders, purchase orders or bundles and cannot be deleted")
+			return
+		}
 		h.logger.Error("delete_product_failed", slog.String("error", err.Error()))
-		h.jsonErr(w, http.StatusInternalServerError, "failed to delete product")
+		jsonErr(w, http.StatusInternalServerError, "failed to delete product")
 		return
 	}
 
-	h.jsonOK(w, http.StatusOK, map[string]string{"message": "product deleted"})
+	jsonOK(w, http.StatusOK, map[string]string{"message": "product deleted"})
 }
 
+func (h *ProductHandler) ActivateProduct(w http.ResponseWriter, r *http.Request) {
+	h.setStatus(w, r, model.ProductActive)
+}
+
+func (h *ProductHandler) DiscontinueProduct(w http.ResponseWriter, r *http.Request) {
+	h.setStatus(w, r, model.ProductDiscontinued)
+}
+
+func (h *ProductHandler) ArchiveProduct(w http.ResponseWriter, r *http.Request) {
+	h.setStatus(w, r, model.ProductArchived)
+}
+
+func (h *ProductHandler) setStatus(w http.ResponseWriter, r *http.Request, to model.ProductStatus) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	if err := h.repo.SetStatus(r.Context(), id, to); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			jsonErr(w, http.StatusNotFound, "product not found")
+		case errors.Is(err, repository.ErrInvalidTransition):
+			jsonErr(w, http.StatusConflict, "product cannot move to that status")
+		default:
+			h.logger.Error("set_product_status_failed", slog.String("error", err.Error()))
+			jsonErr(w, http.StatusInternalServerError, "failed to update product status")
+		}
+		return
+	}
+
+	product, err := h.repo.GetByID(repository.ReadPrimary(r.Context()), id)
+	if err != nil || product == nil {
+		jsonOK(w, http.StatusOK, map[string]string{"message": "product updated"})
+		return
+	}
+	jsonOK(w, http.StatusOK, product)
+}
+
+// Stats carry a weak ETag hashed from the figures; there is no Last-Modified.
 func (h *ProductHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 	stats, err := h.repo.Stats(r.Context())
 	if err != nil {
 		h.logger.Error("get_stats_failed", slog.String("error", err.Error()))
-		h.jsonErr(w, http.StatusInternalServerError, "failed to retrieve stats")
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve stats")
+		return
+	}
+	jsonCached(w, r, hashValidator(stats), stats)
+}
+
+func (h *ProductHandler) ListReorder(w http.ResponseWriter, r *http.Request) {
+	suggestions, err := h.repo.ReorderList(r.Context())
+	if err != nil {
+		h.logger.Error("list_reorder_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve reorder list")
 		return
 	}
-	h.jsonOK(w, http.StatusOK, stats)
+	jsonOK(w, http.StatusOK, suggestions)
 }
 
 func (h *ProductHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	h.jsonOK(w, http.StatusOK, map[string]string{
+	jsonOK(w, http.StatusOK, map[string]string{
 		"status":  "healthy",
 		"service": "storehub",
 	})
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+type PurchasingHandler struct {
+	repo   repository.PurchasingRepository
+	logger *slog.Logger
+}
+
+func NewPurchasingHandler(repo repository.PurchasingRepository, logger *slog.Logger) *PurchasingHandler {
+	return &PurchasingHandler{repo: repo, logger: logger}
+}
+
+func (h *PurchasingHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/suppliers", h.ListSuppliers)
+	mux.HandleFunc("GET /api/suppliers/{id}", h.GetSupplier)
+	mux.HandleFunc("POST /api/suppliers", h.CreateSupplier)
+	mux.HandleFunc("PUT /api/suppliers/{id}", h.UpdateSupplier)
+
+	mux.HandleFunc("GET /api/purchase-orders", h.ListPurchaseOrders)
+	mux.HandleFunc("GET /api/purchase-orders/{id}", h.GetPurchaseOrder)
+	mux.HandleFunc("POST /api/purchase-orders", h.CreatePurchaseOrder)
+	mux.HandleFunc("POST /api/purchase-orders/{id}/send", h.SendPurchaseOrder)
+	mux.HandleFunc("POST /api/purchase-orders/{id}/cancel", h.CancelPurchaseOrder)
+	mux.HandleFunc("POST /api/purchase-orders/{id}/receive", h.ReceivePurchaseOrder)
+}
+
+func (h *PurchasingHandler) ListSuppliers(w http.ResponseWriter, r *http.Request) {
+	suppliers, err := h.repo.ListSuppliers(r.Context())
+	if err != nil {
+		h.logger.Error("list_suppliers_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve suppliers")
+		return
+	}
+	jsonOK(w, http.StatusOK, suppliers)
+}
+
+func (h *PurchasingHandler) GetSupplier(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid supplier ID")
+		return
+	}
+
+	supplier, err := h.repo.GetSupplier(r.Context(), id)
+	if err != nil {
+		h.logger.Error("get_supplier_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve supplier")
+		return
+	}
+	if supplier == nil {
+		jsonErr(w, http.StatusNotFound, "supplier not found")
+		return
+	}
+
+	jsonOK(w, http.StatusOK, supplier)
+}
+
+func (h *PurchasingHandler) CreateSupplier(w http.ResponseWriter, r *http.Request) {
+	var s model.Supplier
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := s.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.repo.CreateSupplier(r.Context(), &s); err != nil {
+		h.logger.Error("create_supplier_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to create supplier")
+		return
+	}
+
+	jsonOK(w, http.StatusCreated, s)
+}
+
+func (h *PurchasingHandler) UpdateSupplier(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid supplier ID")
+		return
+	}
+
+	var s model.Supplier
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	s.ID = id
+
+	if err := s.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.repo.UpdateSupplier(r.Context(), &s); err != nil {
+		if errors.Is(err, repository.ErrSupplierNotFound) {
+			jsonErr(w, http.StatusNotFound, "supplier not found")
+			return
+		}
+		h.logger.Error("update_supplier_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to update supplier")
+		return
+	}
+
+	updated, err := h.repo.GetSupplier(r.Context(), id)
+	if err != nil || updated == nil {
+		jsonOK(w, http.StatusOK, s)
+		return
+	}
+	jsonOK(w, http.StatusOK, updated)
+}
+
+func (h *PurchasingHandler) ListPurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	status := model.PurchaseOrderStatus(r.URL.Query().Get("status"))
+
+	orders, err := h.repo.ListPurchaseOrders(r.Context(), status)
+	if err != nil {
+		h.logger.Error("list_purchase_orders_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve purchase orders")
+		return
+	}
+	jsonOK(w, http.StatusOK, orders)
+}
+
+func (h *PurchasingHandler) GetPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid purchase order ID")
+		return
+	}
+
+	po, err := h.repo.GetPurchaseOrder(r.Context(), id)
+	if err != nil {
+		h.logger.Error("get_purchase_order_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve purchase order")
+		return
+	}
+	if po == nil {
+		jsonErr(w, http.StatusNotFound, "purchase order not found")
+		return
+	}
+
+	jsonOK(w, http.StatusOK, po)
+}
+
+func (h *PurchasingHandler) CreatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	var po model.PurchaseOrder
+	if err := json.NewDecoder(r.Body).Decode(&po); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := po.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.repo.CreatePurchaseOrder(r.Context(), &po); err != nil {
+		h.purchasingErr(w, "create_purchase_order_failed", "failed to create purchase order", err)
+		return
+	}
+
+	jsonOK(w, http.StatusCreated, po)
+}
+
+func (h *PurchasingHandler) SendPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, model.POSent)
+}
+
+func (h *PurchasingHandler) CancelPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, model.POCancelled)
+}
+
+func (h *PurchasingHandler) transition(w http.ResponseWriter, r *http.Request, to model.PurchaseOrderStatus) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid purchase order ID")
+		return
+	}
+
+	if err := h.repo.TransitionPurchaseOrder(r.Context(), id, to); err != nil {
+		h.purchasingErr(w, "transition_purchase_order_failed", "failed to update purchase order", err)
+		return
+	}
+
+	h.respondWithOrder(w, r, id)
+}
+
+func (h *PurchasingHandler) ReceivePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid purchase order ID")
+		return
+	}
+
+	var rcpt model.PurchaseOrderReceipt
+	if err := json.NewDecoder(r.Body).Decode(&rcpt); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := rcpt.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.repo.ReceivePurchaseOrder(r.Context(), id, rcpt); err != nil {
+		h.purchasingErr(w, "receive_purchase_order_failed", "failed to receive purchase order", err)
+		return
+	}
+
+	h.respondWithOrder(w, r, id)
+}
+
+func (h *PurchasingHandler) respondWithOrder(w http.ResponseWriter, r *http.Request, id int64) {
+	po, err := h.repo.GetPurchaseOrder(r.Context(), id)
+	if err != nil || po == nil {
+		jsonOK(w, http.StatusOK, map[string]string{"message": "purchase order updated"})
+		return
+	}
+	jsonOK(w, http.StatusOK, po)
+}
+
+func (h *PurchasingHandler) purchasingErr(w http.ResponseWriter, event, msg string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrPurchaseOrderNotFound):
+		jsonErr(w, http.StatusNotFound, "purchase order not found")
+	case errors.Is(err, repository.ErrSupplierNotFound):
+		jsonErr(w, http.StatusUnprocessableEntity, "supplier not found")
+	case errors.Is(err, repository.ErrWarehouseNotFound):
+		jsonErr(w, http.StatusUnprocessableEntity, "warehouse not found")
+	case errors.Is(err, repository.ErrProductNotFound):
+		jsonErr(w, http.StatusUnprocessableEntity, "product not found")
+	case errors.Is(err, repository.ErrItemNotOnOrder):
+		jsonErr(w, http.StatusUnprocessableEntity, "product is not on this purchase order")
+	case errors.Is(err, repository.ErrOverReceipt):
+		jsonErr(w, http.StatusConflict, "received quantity exceeds quantity ordered")
+	case errors.Is(err, repository.ErrBundleStock):
+		jsonErr(w, http.StatusUnprocessableEntity, "bundles cannot be received; receive their components instead")
+	case errors.Is(err, repository.ErrInvalidTransition):
+		jsonErr(w, http.StatusConflict, "purchase order cannot move to that status")
+	default:
+		h.logger.Error(event, slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, msg)
+	}
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"golang-sql/internal/config"
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+type ReservationHandler struct {
+	repo   repository.ReservationRepository
+	cfg    config.ReservationConfig
+	logger *slog.Logger
+}
+
+func NewReservationHandler(repo repository.ReservationRepository, cfg config.ReservationConfig, logger *slog.Logger) *ReservationHandler {
+	return &ReservationHandler{repo: repo, cfg: cfg, logger: logger}
+}
+
+func (h *ReservationHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/reservations", h.CreateReservation)
+	mux.HandleFunc("GET /api/reservations/{id}", h.GetReservation)
+	mux.HandleFunc("POST /api/reservations/{id}/commit", h.CommitReservation)
+	mux.HandleFunc("DELETE /api/reservations/{id}", h.ReleaseReservation)
+}
+
+func (h *ReservationHandler) CreateReservation(w http.ResponseWriter, r *http.Request) {
+	var res model.Reservation
+	if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := res.Validate(h.cfg.MaxTTL); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if res.TTLSeconds == 0 {
+		res.TTLSeconds = int(h.cfg.DefaultTTL.Seconds())
+	}
+
+	if err := h.repo.Create(r.Context(), &res); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			jsonErr(w, http.StatusNotFound, "product not found")
+		case errors.Is(err, repository.ErrInsufficientStock):
+			jsonErr(w, http.StatusConflict, "insufficient stock")
+		case errors.Is(err, repository.ErrProductNotForSale):
+			jsonErr(w, http.StatusConflict, "product is not for sale")
+		default:
+			h.logger.Error("create_reservation_failed", slog.String("error", err.Error()))
+			jsonErr(w, http.StatusInternalServerError, "failed to create reservation")
+		}
+		return
+	}
+
+	jsonOK(w, http.StatusCreated, res)
+}
+
+func (h *ReservationHandler) GetReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid reservation ID")
+		return
+	}
+
+	res, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Error("get_reservation_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve reservation")
+		return
+	}
+	if res == nil {
+		jsonErr(w, http.StatusNotFound, "reservation not found")
+		return
+	}
+
+	jsonOK(w, http.StatusOK, res)
+}
+
+func (h *ReservationHandler) CommitReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid reservation ID")
+		return
+	}
+
+	var c model.ReservationCommit
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := c.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	m, err := h.repo.Commit(r.Context(), id, c)
+	if err != nil {
+		h.reservationErr(w, "commit_reservation_failed", "failed to commit reservation", err)
+		return
+	}
+
+	jsonOK(w, http.StatusOK, m)
+}
+
+func (h *ReservationHandler) ReleaseReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid reservation ID")
+		return
+	}
+
+	if err := h.repo.Release(r.Context(), id); err != nil {
+		h.reservationErr(w, "release_reservation_failed", "failed to release reservation", err)
+		return
+	}
+
+	jsonOK(w, http.StatusOK, map[string]string{"message": "reservation released"})
+}
+
+func (h *ReservationHandler) reservationErr(w http.ResponseWriter, event, msg string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrReservationNotFound):
+		jsonErr(w, http.StatusNotFound, "reservation not found")
+	case errors.Is(err, repository.ErrReservationNotActive):
+		jsonErr(w, http.StatusConflict, "reservation is no longer active")
+	case errors.Is(err, repository.ErrWarehouseNotFound):
+		jsonErr(w, http.StatusNotFound, "warehouse not found")
+	case errors.Is(err, repository.ErrInsufficientStock):
+		jsonErr(w, http.StatusConflict, "insufficient stock at warehouse")
+	default:
+		h.logger.Error(event, slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, msg)
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"golang-sql/internal/model"
+)
+
+func jsonOK(w http.ResponseWriter, status int, data interface{}) {
+	writeResponse(w, status, jsonEncoder{}, model.APIResponse{Success: true, Data: data})
+}
+
+func jsonErr(w http.ResponseWriter, status int, msg string) {
+	writeResponse(w, status, jsonEncoder{}, model.APIResponse{Success: false, Error: msg})
+}
+
+// respondOK is jsonOK for endpoints that negotiate their format from the
+// request's Accept header.
+func respondOK(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
+	respond(w, r, status, model.APIResponse{Success: true, Data: data})
+}
+
+// respondErr is jsonErr for endpoints that negotiate their format.
+func respondErr(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	respond(w, r, status, model.APIResponse{Success: false, Error: msg})
+}
+
+func respond(w http.ResponseWriter, r *http.Request, status int, resp model.APIResponse) {
+	w.Header().Add("Vary", "Accept")
+	e, ok := negotiate(r.Header.Get("Accept"))
+	if !ok {
+		jsonErr(w, http.StatusNotAcceptable, "cannot produce any of the accepted media types")
+		return
+	}
+	writeResponse(w, status, e.enc, resp)
+}
+
+// respondCached is respondOK for reads that answer conditional requests. The
+// entity tag is specific to the negotiated media type.
+func respondCached(w http.ResponseWriter, r *http.Request, v validator, data interface{}) {
+	w.Header().Add("Vary", "Accept")
+	e, ok := negotiate(r.Header.Get("Accept"))
+	if !ok {
+		jsonErr(w, http.StatusNotAcceptable, "cannot produce any of the accepted media types")
+		return
+	}
+	_, subtype, _ := strings.Cut(e.mediaType, "/")
+	if notModified(w, r, v.etag(subtype), v.modified) {
+		return
+	}
+	writeResponse(w, http.StatusOK, e.enc, model.APIResponse{Success: true, Data: data})
+}
+
+// jsonCached is jsonOK for reads that answer conditional requests.
+func jsonCached(w http.ResponseWriter, r *http.Request, v validator, data interface{}) {
+	if notModified(w, r, v.etag(""), v.modified) {
+		return
+	}
+	jsonOK(w, http.StatusOK, data)
+}
+
+// writeResponse encodes into a buffer first, so that a response the encoder
+// cannot represent is answered with a JSON error rather than a broken body.
+func writeResponse(w http.ResponseWriter, status int, enc Encoder, resp model.APIResponse) {
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, resp); err != nil {
+		if _, isJSON := enc.(jsonEncoder); !isJSON {
+			jsonErr(w, http.StatusNotAcceptable, "response cannot be represented in the requested media type")
+			return
+		}
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", enc.ContentType())
+	w.WriteHeader(status)
+	_, _ = buf.WriteTo(w)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+func (h *ProductHandler) CreateStockMovement(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var m model.StockMovement
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	m.ProductID = id
+
+	if err := m.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.repo.RecordMovement(r.Context(), &m); err != nil {
+		h.stockErr(w, "record_stock_movement_failed", "failed to record stock movement", err)
+		return
+	}
+
+	jsonOK(w, http.StatusCreated, m)
+}
+
+func (h *ProductHandler) ListStockMovements(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	movements, err := h.repo.ListMovements(r.Context(), id, limit)
+	if err != nil {
+		h.logger.Error("list_stock_movements_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve stock movements")
+		return
+	}
+
+	jsonOK(w, http.StatusOK, movements)
+}
+
+func (h *ProductHandler) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var a model.StockAdjustment
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := a.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	m, err := h.repo.AdjustStock(r.Context(), id, a)
+	if err != nil {
+		h.stockErr(w, "adjust_stock_failed", "failed to adjust stock", err)
+		return
+	}
+
+	jsonOK(w, http.StatusOK, m)
+}
+
+func (h *ProductHandler) stockErr(w http.ResponseWriter, event, msg string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrProductNotFound):
+		jsonErr(w, http.StatusNotFound, "product not found")
+	case errors.Is(err, repository.ErrWarehouseNotFound):
+		jsonErr(w, http.StatusNotFound, "warehouse not found")
+	case errors.Is(err, repository.ErrInsufficientStock):
+		jsonErr(w, http.StatusConflict, "insufficient stock")
+	case errors.Is(err, repository.ErrBundleStock):
+		jsonErr(w, http.StatusConflict, "bundles hold no stock; only sales and returns can be booked against them")
+	default:
+		h.logger.Error(event, slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, msg)
+	}
+}
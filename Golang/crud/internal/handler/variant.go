@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+func (h *ProductHandler) ListVariants(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	variants, err := h.repo.ListVariants(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrProductNotFound) {
+			jsonErr(w, http.StatusNotFound, "product not found")
+			return
+		}
+		h.logger.Error("list_variants_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve variants")
+		return
+	}
+
+	jsonOK(w, http.StatusOK, variants)
+}
+
+func (h *ProductHandler) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var v model.Product
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	v.ParentID = &id
+
+	if err := v.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.repo.Create(r.Context(), &v); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			jsonErr(w, http.StatusNotFound, "product not found")
+		case errors.Is(err, repository.ErrInvalidParent):
+			jsonErr(w, http.StatusUnprocessableEntity, "variants can only belong to a top-level product")
+		case errors.Is(err, repository.ErrInvalidTransition):
+			jsonErr(w, http.StatusUnprocessableEntity, "new products must start as draft or active")
+		case errors.Is(err, repository.ErrSKUTaken):
+			jsonErr(w, http.StatusConflict, "sku already in use")
+		case errors.Is(err, repository.ErrCategoryNotFound):
+			jsonErr(w, http.StatusUnprocessableEntity, "category not found")
+		case errors.Is(err, repository.ErrInvalidAttributes):
+			jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			h.logger.Error("create_variant_failed", slog.String("error", err.Error()))
+			jsonErr(w, http.StatusInternalServerError, "failed to create variant")
+		}
+		return
+	}
+
+	jsonOK(w, http.StatusCreated, v)
+}
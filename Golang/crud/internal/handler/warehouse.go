@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+type WarehouseHandler struct {
+	repo   repository.WarehouseRepository
+	logger *slog.Logger
+}
+
+func NewWarehouseHandler(repo repository.WarehouseRepository, logger *slog.Logger) *WarehouseHandler {
+	return &WarehouseHandler{repo: repo, logger: logger}
+}
+
+func (h *WarehouseHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/warehouses", h.ListWarehouses)
+	mux.HandleFunc("POST /api/warehouses", h.CreateWarehouse)
+	mux.HandleFunc("GET /api/warehouses/{id}/stock", h.GetWarehouseStock)
+	mux.HandleFunc("GET /api/products/{id}/stock-levels", h.GetProductStockLevels)
+	mux.HandleFunc("POST /api/transfers", h.CreateTransfer)
+}
+
+func (h *WarehouseHandler) ListWarehouses(w http.ResponseWriter, r *http.Request) {
+	warehouses, err := h.repo.List(r.Context())
+	if err != nil {
+		h.logger.Error("list_warehouses_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve warehouses")
+		return
+	}
+	jsonOK(w, http.StatusOK, warehouses)
+}
+
+func (h *WarehouseHandler) CreateWarehouse(w http.ResponseWriter, r *http.Request) {
+	var wh model.Warehouse
+	if err := json.NewDecoder(r.Body).Decode(&wh); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := wh.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.repo.Create(r.Context(), &wh); err != nil {
+		if errors.Is(err, repository.ErrWarehouseCodeTaken) {
+			jsonErr(w, http.StatusConflict, "warehouse code already exists")
+			return
+		}
+		h.logger.Error("create_warehouse_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to create warehouse")
+		return
+	}
+
+	jsonOK(w, http.StatusCreated, wh)
+}
+
+func (h *WarehouseHandler) GetWarehouseStock(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid warehouse ID")
+		return
+	}
+
+	levels, err := h.repo.StockByWarehouse(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrWarehouseNotFound) {
+			jsonErr(w, http.StatusNotFound, "warehouse not found")
+			return
+		}
+		h.logger.Error("get_warehouse_stock_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve warehouse stock")
+		return
+	}
+
+	jsonOK(w, http.StatusOK, levels)
+}
+
+func (h *WarehouseHandler) GetProductStockLevels(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	levels, err := h.repo.StockByProduct(r.Context(), id)
+	if err != nil {
+		h.logger.Error("get_product_stock_levels_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve stock levels")
+		return
+	}
+
+	jsonOK(w, http.StatusOK, levels)
+}
+
+func (h *WarehouseHandler) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	var t model.StockTransfer
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := t.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	movements, err := h.repo.Transfer(r.Context(), t)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			jsonErr(w, http.StatusNotFound, "product not found")
+		case errors.Is(err, repository.ErrWarehouseNotFound):
+			jsonErr(w, http.StatusNotFound, "warehouse not found")
+		case errors.Is(err, repository.ErrInsufficientStock):
+			jsonErr(w, http.StatusConflict, "insufficient stock at source warehouse")
+		default:
+			h.logger.Error("create_transfer_failed", slog.String("error", err.Error()))
+			jsonErr(w, http.StatusInternalServerError, "failed to transfer stock")
+		}
+		return
+	}
+
+	jsonOK(w, http.StatusCreated, movements)
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+	"golang-sql/internal/webhook"
+)
+
+type WebhookHandler struct {
+	repo   repository.WebhookRepository
+	logger *slog.Logger
+}
+
+func NewWebhookHandler(repo repository.WebhookRepository, logger *slog.Logger) *WebhookHandler {
+	return &WebhookHandler{repo: repo, logger: logger}
+}
+
+func (h *WebhookHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/webhooks", h.ListWebhooks)
+	mux.HandleFunc("POST /api/webhooks", h.CreateWebhook)
+	mux.HandleFunc("GET /api/webhooks/{id}", h.GetWebhook)
+	mux.HandleFunc("PUT /api/webhooks/{id}", h.UpdateWebhook)
+	mux.HandleFunc("DELETE /api/webhooks/{id}", h.DeleteWebhook)
+	mux.HandleFunc("GET /api/webhooks/{id}/deliveries", h.ListDeliveries)
+	mux.HandleFunc("GET /api/webhooks/dead-letters", h.ListDeadLetters)
+	mux.HandleFunc("POST /api/webhooks/deliveries/{id}/retry", h.RetryDelivery)
+}
+
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.repo.List(r.Context())
+	if err != nil {
+		h.logger.Error("list_webhooks_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve webhooks")
+		return
+	}
+	jsonOK(w, http.StatusOK, subs)
+}
+
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid webhook ID")
+		return
+	}
+
+	sub, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Error("get_webhook_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve webhook")
+		return
+	}
+	if sub == nil {
+		jsonErr(w, http.StatusNotFound, "webhook not found")
+		return
+	}
+	jsonOK(w, http.StatusOK, sub)
+}
+
+// CreateWebhook registers a subscription, generating a signing secret if the
+// request has none. The response is the only place the secret is shown.
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	s := model.WebhookSubscription{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+
+	if err := s.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if s.Secret == "" {
+		s.Secret = webhook.NewSecret()
+	}
+
+	if err := h.repo.Create(r.Context(), &s); err != nil {
+		h.logger.Error("create_webhook_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to create webhook")
+		return
+	}
+	jsonOK(w, http.StatusCreated, s)
+}
+
+// UpdateWebhook replaces the URL, event types and active flag. A secret in
+// the request rotates the signing secret; without one it is kept.
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid webhook ID")
+		return
+	}
+
+	s := model.WebhookSubscription{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid JSON payload")
+		return
+	}
+	s.ID = id
+
+	if err := s.Validate(); err != nil {
+		jsonErr(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := h.repo.Update(r.Context(), &s); err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			jsonErr(w, http.StatusNotFound, "webhook not found")
+			return
+		}
+		h.logger.Error("update_webhook_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to update webhook")
+		return
+	}
+
+	updated, err := h.repo.GetByID(r.Context(), id)
+	if err != nil || updated == nil {
+		s.Secret = ""
+		jsonOK(w, http.StatusOK, s)
+		return
+	}
+	jsonOK(w, http.StatusOK, updated)
+}
+
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid webhook ID")
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			jsonErr(w, http.StatusNotFound, "webhook not found")
+			return
+		}
+		h.logger.Error("delete_webhook_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to delete webhook")
+		return
+	}
+	jsonOK(w, http.StatusOK, map[string]string{"message": "webhook deleted"})
+}
+
+// ListDeliveries is the delivery log of one subscription, newest first,
+// optionally narrowed to one status.
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid webhook ID")
+		return
+	}
+	status := model.WebhookDeliveryStatus(r.URL.Query().Get("status"))
+	if status != "" && !status.Valid() {
+		jsonErr(w, http.StatusBadRequest, "status must be one of pending, succeeded, dead")
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	sub, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Error("get_webhook_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve webhook")
+		return
+	}
+	if sub == nil {
+		jsonErr(w, http.StatusNotFound, "webhook not found")
+		return
+	}
+
+	h.listDeliveries(w, r, id, status, limit)
+}
+
+// ListDeadLetters lists the deliveries of every subscription that ran out of
+// attempts.
+func (h *WebhookHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	h.listDeliveries(w, r, 0, model.DeliveryDead, limit)
+}
+
+func (h *WebhookHandler) listDeliveries(w http.ResponseWriter, r *http.Request, subscriptionID int64, status model.WebhookDeliveryStatus, limit int) {
+	deliveries, err := h.repo.ListDeliveries(r.Context(), subscriptionID, status, limit)
+	if err != nil {
+		h.logger.Error("list_webhook_deliveries_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retrieve deliveries")
+		return
+	}
+	jsonOK(w, http.StatusOK, deliveries)
+}
+
+// RetryDelivery queues a delivery, typically a dead letter, to be sent again
+// with a fresh set of attempts.
+func (h *WebhookHandler) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonErr(w, http.StatusBadRequest, "invalid delivery ID")
+		return
+	}
+
+	if err := h.repo.Redeliver(r.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrDeliveryNotFound) {
+			jsonErr(w, http.StatusNotFound, "delivery not found")
+			return
+		}
+		h.logger.Error("retry_webhook_delivery_failed", slog.String("error", err.Error()))
+		jsonErr(w, http.StatusInternalServerError, "failed to retry delivery")
+		return
+	}
+	jsonOK(w, http.StatusAccepted, map[string]string{"message": "delivery queued"})
+}
@@ -0,0 +1,132 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrInvalidImage    = errors.New("invalid image")
+)
+
+// maxPixels bounds the decoded size of an upload, so a small file that
+// claims huge dimensions cannot exhaust memory.
+const maxPixels = 40_000_000
+
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Image is an upload that has been sniffed, decoded and thumbnailed.
+type Image struct {
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+
+	Thumbnail     []byte
+	ThumbnailType string
+	ThumbnailExt  string
+}
+
+// Process identifies data by its content rather than its file name or the
+// client's Content-Type, and renders a thumbnail that fits in a size×size
+// square. JPEG uploads get JPEG thumbnails; PNG and GIF get PNG ones so
+// transparency survives.
+func Process(data []byte, size int) (*Image, error) {
+	ct := http.DetectContentType(data)
+	ext, ok := extensions[ct]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", ct, ErrUnsupportedType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("reading image header: %w", ErrInvalidImage)
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("%dx%d exceeds %d pixels: %w", cfg.Width, cfg.Height, maxPixels, ErrInvalidImage)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %w", ErrInvalidImage)
+	}
+
+	img := &Image{
+		ContentType: ct,
+		Ext:         ext,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}
+
+	var buf bytes.Buffer
+	thumb := thumbnail(src, size)
+	if ct == "image/jpeg" {
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85})
+		img.ThumbnailType, img.ThumbnailExt = "image/jpeg", ".jpg"
+	} else {
+		err = png.Encode(&buf, thumb)
+		img.ThumbnailType, img.ThumbnailExt = "image/png", ".png"
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encoding thumbnail: %w", err)
+	}
+	img.Thumbnail = buf.Bytes()
+	return img, nil
+}
+
+// thumbnail scales src down to fit in a size×size square, averaging every
+// source pixel that falls into each destination pixel. Images that already
+// fit are returned unscaled.
+func thumbnail(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if sw <= size && sh <= size {
+		return src
+	}
+
+	tw, th := size, size
+	if sw > sh {
+		th = max(1, sh*size/sw)
+	} else {
+		tw = max(1, sw*size/sh)
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, sw, sh))
+	draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := y*sh/th, (y+1)*sh/th
+		for x := 0; x < tw; x++ {
+			x0, x1 := x*sw/tw, (x+1)*sw/tw
+
+			var r, g, bl, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += int(p[0])
+					g += int(p[1])
+					bl += int(p[2])
+					a += int(p[3])
+					n++
+				}
+			}
+
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0], d[1], d[2], d[3] = uint8(r/n), uint8(g/n), uint8(bl/n), uint8(a/n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,89 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+)
+
+var ErrNotFound = errors.New("media not found")
+
+// Store keeps uploaded files under slash-separated keys. LocalStore writes
+// them to disk; an S3-compatible bucket can be used instead by implementing
+// the same three methods.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// LocalStore keeps files below a directory. Keys are resolved through an
+// os.Root, so they cannot escape it.
+type LocalStore struct {
+	root *os.Root
+}
+
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating media directory: %w", err)
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("opening media directory: %w", err)
+	}
+	return &LocalStore{root: root}, nil
+}
+
+func (s *LocalStore) Close() error {
+	return s.root.Close()
+}
+
+// Put writes to a temporary file first and renames it into place, so a
+// reader never sees a half-written file.
+func (s *LocalStore) Put(_ context.Context, key string, r io.Reader) error {
+	if err := s.root.MkdirAll(path.Dir(key), 0o755); err != nil {
+		return fmt.Errorf("creating directory for %s: %w", key, err)
+	}
+
+	tmp := key + ".tmp"
+	f, err := s.root.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", key, err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		s.root.Remove(tmp)
+		return fmt.Errorf("writing %s: %w", key, err)
+	}
+	if err := f.Close(); err != nil {
+		s.root.Remove(tmp)
+		return fmt.Errorf("closing %s: %w", key, err)
+	}
+	if err := s.root.Rename(tmp, key); err != nil {
+		s.root.Remove(tmp)
+		return fmt.Errorf("storing %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *LocalStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	f, err := s.root.Open(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", key, err)
+	}
+	return f, nil
+}
+
+func (s *LocalStore) Delete(_ context.Context, key string) error {
+	if err := s.root.Remove(key); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("deleting %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressor is a pooled stream encoder for one Content-Encoding. Both
+// *gzip.Writer and *zstd.Encoder satisfy it.
+type compressor interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+	Flush() error
+}
+
+type codec struct {
+	name string
+	pool sync.Pool
+}
+
+// codecs in order of preference when a client accepts several equally.
+var codecs = []*codec{
+	{name: "zstd", pool: sync.Pool{New: func() any {
+		// Options are fixed, so NewWriter cannot fail. A 1 MiB window keeps
+		// memory per response small and is within what browsers accept.
+		enc, _ := zstd.NewWriter(nil,
+			zstd.WithEncoderConcurrency(1),
+			zstd.WithWindowSize(1<<20),
+		)
+		return enc
+	}}},
+	{name: "gzip", pool: sync.Pool{New: func() any {
+		return gzip.NewWriter(nil)
+	}}},
+}
+
+// Compress encodes responses with zstd or gzip, whichever the client prefers
+// in Accept-Encoding. Bodies are held back until minSize bytes have been
+// written, so small responses go out as they are. Responses that already
+// have a Content-Encoding, partial content and media types that are
+// compressed already (images, archives, spreadsheets) are left alone.
+// Flushing a response commits to compressing it, so streams work.
+func Compress(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			c := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if c == nil || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Not deferred: after a panic the response is left to Recovery.
+			cw := &compressWriter{ResponseWriter: w, codec: c, minSize: minSize}
+			next.ServeHTTP(cw, r)
+			cw.finish()
+		})
+	}
+}
+
+// negotiateEncoding returns the codec to use for an Accept-Encoding header, or
+// nil when the client accepts none of them. As in RFC 9110, "*" stands for
+// the codings the header does not name, so a codec refused with q=0 stays
+// refused whatever the wildcard says.
+func negotiateEncoding(header string) *codec {
+	named := make(map[string]float64)
+	wildcard := 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if name = strings.ToLower(strings.TrimSpace(name)); name == "*" {
+			wildcard = q
+		} else {
+			named[name] = q
+		}
+	}
+
+	var (
+		best  *codec
+		bestQ float64
+	)
+	for _, c := range codecs {
+		q, ok := named[c.name]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = c, q
+		}
+	}
+	return best
+}
+
+type compressWriter struct {
+	http.ResponseWriter
+	codec   *codec
+	minSize int
+
+	status  int
+	buf     []byte
+	decided bool
+	enc     compressor
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if code < 200 {
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if cw.status != 0 {
+		return
+	}
+	cw.status = code
+	if code == http.StatusNoContent || code == http.StatusNotModified {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	if !cw.decided {
+		cw.buf = append(cw.buf, b...)
+		if len(cw.buf) < cw.minSize {
+			return len(b), nil
+		}
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if cw.enc != nil {
+		return cw.enc.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Flush sends what has been written so far, compressing the response from
+// here on if it can be compressed at all.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if cw.status == 0 {
+			cw.status = http.StatusOK
+		}
+		_ = cw.decide(true)
+	}
+	if cw.enc != nil {
+		_ = cw.enc.Flush()
+	}
+	_ = http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// decide writes the header, compressed or not, followed by anything buffered.
+func (cw *compressWriter) decide(large bool) error {
+	cw.decided = true
+	if large && cw.compressible() {
+		h := cw.Header()
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", cw.codec.name)
+		// The compressed bytes differ from the ones a strong validator
+		// vouches for; they are still semantically the same resource.
+		if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+			h.Set("ETag", "W/"+etag)
+		}
+		cw.enc = cw.codec.pool.Get().(compressor)
+		cw.enc.Reset(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (cw *compressWriter) compressible() bool {
+	h := cw.Header()
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" || cw.status == http.StatusPartialContent {
+		return false
+	}
+	ct := h.Get("Content-Type")
+	if ct == "" {
+		// Sniff now, as net/http would, so that the sniffing sees the
+		// uncompressed bytes.
+		ct = http.DetectContentType(cw.buf)
+		h.Set("Content-Type", ct)
+	}
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mediaType == "image/svg+xml":
+		return true
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "font/woff"),
+		strings.HasPrefix(mediaType, "application/vnd.openxmlformats-"):
+		return false
+	}
+	switch mediaType {
+	case "application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+		"application/x-7z-compressed", "application/x-rar-compressed", "application/pdf":
+		return false
+	}
+	return true
+}
+
+// finish completes the response once the handler has returned.
+func (cw *compressWriter) finish() {
+	if !cw.decided {
+		if cw.status == 0 {
+			cw.status = http.StatusOK
+		}
+		_ = cw.decide(len(cw.buf) >= cw.minSize)
+	}
+	if cw.enc != nil {
+		_ = cw.enc.Close()
+		cw.enc.Reset(nil)
+		cw.codec.pool.Put(cw.enc)
+		cw.enc = nil
+	}
+}
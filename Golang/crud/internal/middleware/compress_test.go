@@ -0,0 +1,35 @@
+package middleware
+
+import "testing"
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string // "" when no codec is acceptable
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"zstd", "zstd"},
+		{"gzip, deflate, br, zstd", "zstd"},
+		{"GZIP", "gzip"},
+		{"gzip;q=1.0, zstd;q=0.5", "gzip"},
+		{"gzip;q=0.5, zstd;q=0.8", "zstd"},
+		{"*", "zstd"},
+		{"zstd;q=0, *", "gzip"},
+		{"*, zstd;q=0", "gzip"},
+		{"gzip;q=0, zstd;q=0, *", ""},
+		{"*;q=0", ""},
+		{"*;q=0.1, gzip", "gzip"},
+		{"gzip;q=bad, zstd", "zstd"},
+	}
+	for _, tt := range tests {
+		got := ""
+		if c := negotiateEncoding(tt.header); c != nil {
+			got = c.name
+		}
+		if got != tt.want {
+			t.Errorf("Accept-Encoding %q: got %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeoutExempt(t *testing.T) {
+	tests := []struct {
+		path   string
+		accept string
+		want   bool // whether the request gets a deadline
+	}{
+		{"/api/products", "", true},
+		{"/api/products", "text/event-stream", true},
+		{"/api/events", "text/event-stream", false},
+		{"/api/events/", "", true},
+	}
+	for _, tt := range tests {
+		var got bool
+		h := Timeout(time.Second, "/api/events")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, got = r.Context().Deadline()
+		}))
+		r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		r.Header.Set("Accept", tt.accept)
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		if got != tt.want {
+			t.Errorf("%s with Accept %q: deadline %v, want %v", tt.path, tt.accept, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package model
+
+import "errors"
+
+type BatchAction string
+
+const (
+	BatchCreate BatchAction = "create"
+	BatchUpdate BatchAction = "update"
+	BatchDelete BatchAction = "delete"
+)
+
+// BatchOperation is one create, update or delete in a batch request. Updates
+// and deletes name their product by ID; creates and updates carry the full
+// product, as for the single-product endpoints.
+type BatchOperation struct {
+	Action  BatchAction `json:"action"`
+	ID      int64       `json:"id,omitempty"`
+	Product *Product    `json:"product,omitempty"`
+}
+
+func (op *BatchOperation) Validate() error {
+	switch op.Action {
+	case BatchCreate:
+		if op.ID != 0 {
+			return errors.New("create must not name an id")
+		}
+		if op.Product == nil {
+			return errors.New("create needs a product")
+		}
+		op.Product.ID = 0
+	case BatchUpdate:
+		if op.ID <= 0 {
+			return errors.New("update needs an id")
+		}
+		if op.Product == nil {
+			return errors.New("update needs a product")
+		}
+		op.Product.ID = op.ID
+	case BatchDelete:
+		if op.ID <= 0 {
+			return errors.New("delete needs an id")
+		}
+		if op.Product != nil {
+			return errors.New("delete must not carry a product")
+		}
+		return nil
+	default:
+		return errors.New("action must be one of create, update, delete")
+	}
+	return op.Product.Validate()
+}
+
+// BatchRequest is the body of a batch call. Atomic batches are applied in one
+// transaction and only take effect if every operation succeeds; otherwise each
+// operation stands on its own.
+type BatchRequest struct {
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchResult reports the outcome of one operation, with the HTTP status the
+// matching single-product request would have returned.
+type BatchResult struct {
+	Index   int         `json:"index"`
+	Action  BatchAction `json:"action"`
+	ID      int64       `json:"id,omitempty"`
+	Status  int         `json:"status"`
+	Error   string      `json:"error,omitempty"`
+	Product *Product    `json:"product,omitempty"`
+}
+
+type BatchResponse struct {
+	Atomic    bool          `json:"atomic"`
+	Committed bool          `json:"committed"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Results   []BatchResult `json:"results"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import "errors"
+
+// BundleComponent is one product that goes into a bundle, and how many units
+// of it each bundle consumes.
+type BundleComponent struct {
+	ComponentID  int64  `json:"component_id"`
+	Name         string `json:"name"`
+	Quantity     int    `json:"quantity"`
+	AvailableQty int    `json:"available_quantity"`
+}
+
+// Bundle is a kit sold as one product. It holds no stock of its own: its
+// availability is the number of complete kits the components can make, and
+// selling it deducts every component.
+type Bundle struct {
+	ProductID    int64             `json:"product_id"`
+	AvailableQty int               `json:"available_quantity"`
+	Components   []BundleComponent `json:"components"`
+}
+
+func (b *Bundle) Validate() error {
+	if len(b.Components) > 50 {
+		return errors.New("a bundle may have at most 50 components")
+	}
+
+	seen := make(map[int64]bool, len(b.Components))
+	for _, c := range b.Components {
+		if c.ComponentID <= 0 {
+			return errors.New("every component needs a component_id")
+		}
+		if c.ComponentID == b.ProductID {
+			return errors.New("a bundle cannot contain itself")
+		}
+		if seen[c.ComponentID] {
+			return errors.New("each component may appear only once per bundle")
+		}
+		seen[c.ComponentID] = true
+		if c.Quantity <= 0 {
+			return errors.New("component quantity must be positive")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type AttributeType string
+
+const (
+	AttrString  AttributeType = "string"
+	AttrNumber  AttributeType = "number"
+	AttrBoolean AttributeType = "boolean"
+	AttrEnum    AttributeType = "enum"
+)
+
+var attributeName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// AttributeDef describes one specification products in a category may carry,
+// e.g. ram (number, GB) or colour (enum of Black, Silver).
+type AttributeDef struct {
+	Name     string        `json:"name"`
+	Type     AttributeType `json:"type"`
+	Unit     string        `json:"unit,omitempty"`
+	Required bool          `json:"required"`
+	Values   []string      `json:"values,omitempty"`
+}
+
+// Category groups products that share an attribute schema. Changing the
+// schema does not revalidate products already in the category.
+type Category struct {
+	ID         int64          `json:"id"`
+	Name       string         `json:"name"`
+	Attributes []AttributeDef `json:"attributes"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+func (c *Category) Validate() error {
+	c.Name = strings.TrimSpace(c.Name)
+
+	if c.Name == "" {
+		return errors.New("category name is required")
+	}
+	if len(c.Name) > 100 {
+		return errors.New("category name must be 100 characters or less")
+	}
+	if len(c.Attributes) > 50 {
+		return errors.New("a category may define at most 50 attributes")
+	}
+
+	seen := make(map[string]bool, len(c.Attributes))
+	for i := range c.Attributes {
+		a := &c.Attributes[i]
+		a.Unit = strings.TrimSpace(a.Unit)
+		if !attributeName.MatchString(a.Name) {
+			return errors.New("attribute names must be lowercase letters, digits and underscores, up to 50 characters")
+		}
+		if seen[a.Name] {
+			return fmt.Errorf("attribute %s is defined twice", a.Name)
+		}
+		seen[a.Name] = true
+		if len(a.Unit) > 20 {
+			return fmt.Errorf("unit of %s must be 20 characters or less", a.Name)
+		}
+
+		switch a.Type {
+		case AttrString, AttrNumber, AttrBoolean:
+			if len(a.Values) > 0 {
+				return fmt.Errorf("only enum attributes take values, %s is %s", a.Name, a.Type)
+			}
+		case AttrEnum:
+			if len(a.Values) == 0 {
+				return fmt.Errorf("enum attribute %s needs at least one value", a.Name)
+			}
+		default:
+			return fmt.Errorf("type of %s must be one of string, number, boolean, enum", a.Name)
+		}
+	}
+	return nil
+}
+
+// CheckAttributes enforces the category's schema on a product's attributes:
+// every attribute must be defined, required ones must be present and each
+// value must have the defined type.
+func (c *Category) CheckAttributes(attrs map[string]any) error {
+	defs := make(map[string]AttributeDef, len(c.Attributes))
+	for _, d := range c.Attributes {
+		defs[d.Name] = d
+		if _, ok := attrs[d.Name]; d.Required && !ok {
+			return fmt.Errorf("%s is required in category %s", d.Name, c.Name)
+		}
+	}
+
+	for name, v := range attrs {
+		d, ok := defs[name]
+		if !ok {
+			return fmt.Errorf("%s is not an attribute of category %s", name, c.Name)
+		}
+		switch d.Type {
+		case AttrNumber:
+			if _, ok := v.(float64); !ok {
+				return fmt.Errorf("%s must be a number", name)
+			}
+		case AttrBoolean:
+			if _, ok := v.(bool); !ok {
+				return fmt.Errorf("%s must be true or false", name)
+			}
+		case AttrString:
+			if _, ok := v.(string); !ok {
+				return fmt.Errorf("%s must be a string", name)
+			}
+		case AttrEnum:
+			s, ok := v.(string)
+			if !ok || !slices.Contains(d.Values, s) {
+				return fmt.Errorf("%s must be one of %s", name, strings.Join(d.Values, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// validateAttributes checks the shape of free-form attributes: valid names,
+// and string, number or boolean values.
+func validateAttributes(attrs map[string]any) error {
+	if len(attrs) > 50 {
+		return errors.New("a product may have at most 50 attributes")
+	}
+	for name, v := range attrs {
+		if !attributeName.MatchString(name) {
+			return errors.New("attribute names must be lowercase letters, digits and underscores, up to 50 characters")
+		}
+		switch v := v.(type) {
+		case float64, bool:
+		case string:
+			if len(v) > 255 {
+				return fmt.Errorf("value of %s must be 255 characters or less", name)
+			}
+		default:
+			return fmt.Errorf("value of %s must be a string, number or boolean", name)
+		}
+	}
+	return nil
+}
+
+type AttributeOp string
+
+const (
+	AttrEq  AttributeOp = "="
+	AttrGte AttributeOp = ">="
+	AttrLte AttributeOp = "<="
+)
+
+// AttributeFilter matches products by one attribute. Range operators only
+// match numeric attributes.
+type AttributeFilter struct {
+	Name  string
+	Op    AttributeOp
+	Value string
+}
+
+// ParseAttributeFilter reads filters of the form name:value, name>=n or
+// name<=n, as given in the attr query parameter.
+func ParseAttributeFilter(s string) (AttributeFilter, error) {
+	var f AttributeFilter
+	for _, sep := range []struct {
+		token string
+		op    AttributeOp
+	}{{">=", AttrGte}, {"<=", AttrLte}, {":", AttrEq}} {
+		if name, value, ok := strings.Cut(s, sep.token); ok {
+			f = AttributeFilter{Name: name, Op: sep.op, Value: value}
+			break
+		}
+	}
+
+	if !attributeName.MatchString(f.Name) {
+		return f, fmt.Errorf("attribute filter %q must look like name:value, name>=n or name<=n", s)
+	}
+	if f.Op != AttrEq {
+		if _, err := strconv.ParseFloat(f.Value, 64); err != nil {
+			return f, fmt.Errorf("attribute filter %q needs a number", s)
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+type EventType string
+
+const (
+	EventProductCreated EventType = "product.created"
+	EventProductUpdated EventType = "product.updated"
+	EventProductDeleted EventType = "product.deleted"
+	EventStockLow       EventType = "stock.low"
+)
+
+// EventTypes lists every event type, in the order they are documented.
+var EventTypes = []EventType{EventProductCreated, EventProductUpdated, EventProductDeleted, EventStockLow}
+
+// Event records a committed change to a product. Product is the product as
+// it stood after the change, and is nil for deletions. Stock movements and
+// reservations are recorded as product.updated, since they change its
+// quantities. stock.low is recorded when stock on hand falls from above the
+// product's reorder point to at or below it.
+type Event struct {
+	ID         int64     `json:"id"`
+	Type       EventType `json:"type"`
+	ProductID  int64     `json:"product_id"`
+	Product    *Product  `json:"product,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
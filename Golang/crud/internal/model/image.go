@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// MediaPath is the URL prefix uploaded files are served under.
+const MediaPath = "/media/"
+
+// ProductImage is an uploaded picture of a product together with its
+// thumbnail. Images are shown in Position order.
+type ProductImage struct {
+	ID           int64     `json:"id"`
+	ProductID    int64     `json:"product_id"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	ContentType  string    `json:"content_type"`
+	SizeBytes    int64     `json:"size_bytes"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	Position     int       `json:"position"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	StorageKey   string `json:"-"`
+	ThumbnailKey string `json:"-"`
+}
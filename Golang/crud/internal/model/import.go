@@ -0,0 +1,156 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ImportColumns are the CSV columns the product import understands, named as
+// in the JSON API. Rows are matched on id when it is filled in and on sku
+// otherwise; a blank cell leaves the stored value unchanged. stock_quantity
+// only sets the initial stock of new products, as updates never change stock.
+var ImportColumns = []string{
+	"id", "sku", "name", "description", "price",
+	"stock_quantity", "reorder_point", "reorder_quantity", "status", "category_id",
+}
+
+// importIgnored are the read-only columns of a product export. They are
+// accepted and skipped so that an exported file can be edited and imported
+// again as it is.
+var importIgnored = []string{
+	"parent_id", "price_override", "reserved_quantity", "available_quantity", "is_bundle", "created_at", "updated_at",
+}
+
+// ImportHeader maps each column of an import file to its position.
+type ImportHeader map[string]int
+
+func ParseImportHeader(record []string) (ImportHeader, error) {
+	h := make(ImportHeader, len(record))
+	for i, name := range record {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if slices.Contains(importIgnored, name) {
+			continue
+		}
+		if !slices.Contains(ImportColumns, name) {
+			return nil, fmt.Errorf("unknown column %q; columns are %s", name, strings.Join(ImportColumns, ", "))
+		}
+		if _, dup := h[name]; dup {
+			return nil, fmt.Errorf("column %q appears more than once", name)
+		}
+		h[name] = i
+	}
+	_, hasID := h["id"]
+	_, hasSKU := h["sku"]
+	if !hasID && !hasSKU {
+		return nil, errors.New("csv needs an id or sku column")
+	}
+	return h, nil
+}
+
+func (h ImportHeader) cell(record []string, column string) string {
+	i, ok := h[column]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// Key returns the ID and SKU the row is matched on; id is zero when the row
+// only has a SKU.
+func (h ImportHeader) Key(record []string) (id int64, sku string, err error) {
+	sku = h.cell(record, "sku")
+	if s := h.cell(record, "id"); s != "" {
+		id, err = strconv.ParseInt(s, 10, 64)
+		if err != nil || id <= 0 {
+			return 0, "", errors.New("id must be a positive integer")
+		}
+	}
+	if id == 0 && sku == "" {
+		return 0, "", errors.New("row needs an id or sku")
+	}
+	return id, sku, nil
+}
+
+// Apply copies the non-blank cells of record onto p. A variant given a price
+// other than the one it sells at gets it as its price override, as when
+// editing a variant by hand.
+func (h ImportHeader) Apply(record []string, p *Product) error {
+	if v := h.cell(record, "sku"); v != "" {
+		p.SKU = v
+	}
+	if v := h.cell(record, "name"); v != "" {
+		p.Name = v
+	}
+	if v := h.cell(record, "description"); v != "" {
+		p.Description = v
+	}
+	if v := h.cell(record, "price"); v != "" {
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return errors.New("price must be a number")
+		}
+		if p.ParentID != nil {
+			if price != p.Price {
+				p.PriceOverride = &price
+			}
+		} else {
+			p.Price = price
+		}
+	}
+	for _, c := range []struct {
+		column string
+		dst    *int
+	}{
+		{"stock_quantity", &p.StockQty},
+		{"reorder_point", &p.ReorderPoint},
+		{"reorder_quantity", &p.ReorderQty},
+	} {
+		if v := h.cell(record, c.column); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%s must be an integer", c.column)
+			}
+			*c.dst = n
+		}
+	}
+	if v := h.cell(record, "status"); v != "" {
+		p.Status = ProductStatus(strings.ToLower(v))
+	}
+	if v := h.cell(record, "category_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			return errors.New("category_id must be a positive integer")
+		}
+		p.CategoryID = &id
+	}
+	return nil
+}
+
+// ImportRowResult reports what happened to one data row of an import file.
+// Line is the line the row starts on, counting the header as line 1.
+type ImportRowResult struct {
+	Line   int         `json:"line"`
+	Action BatchAction `json:"action,omitempty"`
+	ID     int64       `json:"id,omitempty"`
+	SKU    string      `json:"sku,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// ImportReport summarises an import. In a dry run nothing is written and the
+// counts say what the import would have done. Error is set when the file
+// could not be read to the end; rows after that point were not looked at.
+type ImportReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Rows    int               `json:"rows"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Failed  int               `json:"failed"`
+	Error   string            `json:"error,omitempty"`
+	Results []ImportRowResult `json:"results"`
+}
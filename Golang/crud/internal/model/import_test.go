@@ -0,0 +1,150 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseImportHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  []string
+		want    ImportHeader
+		wantErr string
+	}{
+		{
+			name:   "id and sku",
+			record: []string{"id", "sku", "name"},
+			want:   ImportHeader{"id": 0, "sku": 1, "name": 2},
+		},
+		{
+			name:   "byte order mark, case and spaces",
+			record: []string{"\ufeffSKU", " Price "},
+			want:   ImportHeader{"sku": 0, "price": 1},
+		},
+		{
+			name:   "export columns are skipped",
+			record: []string{"id", "parent_id", "reserved_quantity", "name", "updated_at"},
+			want:   ImportHeader{"id": 0, "name": 3},
+		},
+		{name: "unknown column", record: []string{"sku", "colour"}, wantErr: `unknown column "colour"`},
+		{name: "duplicate column", record: []string{"sku", "name", "Name"}, wantErr: `"name" appears more than once`},
+		{name: "no key", record: []string{"name", "price"}, wantErr: "needs an id or sku column"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseImportHeader(tt.record)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestImportHeaderKey(t *testing.T) {
+	h, err := ParseImportHeader([]string{"id", "sku"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		record  []string
+		id      int64
+		sku     string
+		wantErr bool
+	}{
+		{[]string{"7", "W-1"}, 7, "W-1", false},
+		{[]string{"", " W-1 "}, 0, "W-1", false},
+		{[]string{"7"}, 7, "", false},
+		{[]string{"", ""}, 0, "", true},
+		{[]string{"-3", "W-1"}, 0, "", true},
+		{[]string{"x", "W-1"}, 0, "", true},
+	}
+	for _, tt := range tests {
+		id, sku, err := h.Key(tt.record)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: error %v, want error %v", tt.record, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (id != tt.id || sku != tt.sku) {
+			t.Errorf("%q: got %d %q, want %d %q", tt.record, id, sku, tt.id, tt.sku)
+		}
+	}
+}
+
+func TestImportHeaderApply(t *testing.T) {
+	h, err := ParseImportHeader([]string{"sku", "name", "price", "stock_quantity", "reorder_point", "status", "category_id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := Product{SKU: "W-1", Name: "Widget", Description: "kept", Price: 5, ReorderPoint: 10}
+	if err := h.Apply([]string{"W-1", "", "7.50", "3", "", "Draft", "4"}, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Widget" || p.Description != "kept" || p.ReorderPoint != 10 {
+		t.Errorf("blank cells changed the product: %+v", p)
+	}
+	if p.Price != 7.5 || p.StockQty != 3 || p.Status != ProductDraft || p.CategoryID == nil || *p.CategoryID != 4 {
+		t.Errorf("filled cells not applied: %+v", p)
+	}
+}
+
+func TestImportHeaderApplyVariantPrice(t *testing.T) {
+	h, err := ParseImportHeader([]string{"sku", "price"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := int64(1)
+
+	same := Product{ParentID: &parent, Price: 20}
+	if err := h.Apply([]string{"V-1", "20"}, &same); err != nil {
+		t.Fatal(err)
+	}
+	if same.PriceOverride != nil {
+		t.Errorf("price equal to the inherited one set an override of %v", *same.PriceOverride)
+	}
+
+	other := Product{ParentID: &parent, Price: 20}
+	if err := h.Apply([]string{"V-1", "25"}, &other); err != nil {
+		t.Fatal(err)
+	}
+	if other.PriceOverride == nil || *other.PriceOverride != 25 || other.Price != 20 {
+		t.Errorf("got price %v override %v, want 20 with override 25", other.Price, other.PriceOverride)
+	}
+}
+
+func TestImportHeaderApplyInvalid(t *testing.T) {
+	h, err := ParseImportHeader([]string{"sku", "price", "reorder_point", "category_id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		record  []string
+		wantErr string
+	}{
+		{[]string{"W-1", "cheap", "", ""}, "price must be a number"},
+		{[]string{"W-1", "", "ten", ""}, "reorder_point must be an integer"},
+		{[]string{"W-1", "", "", "0"}, "category_id must be a positive integer"},
+	}
+	for _, tt := range tests {
+		var p Product
+		err := h.Apply(tt.record, &p)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%q: got error %v, want %q", tt.record, err, tt.wantErr)
+		}
+	}
+}
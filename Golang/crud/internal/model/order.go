@@ -0,0 +1,111 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+type OrderStatus string
+
+const (
+	OrderPending   OrderStatus = "pending"
+	OrderPaid      OrderStatus = "paid"
+	OrderFulfilled OrderStatus = "fulfilled"
+	OrderCancelled OrderStatus = "cancelled"
+)
+
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderPending: {OrderPaid, OrderCancelled},
+	OrderPaid:    {OrderFulfilled, OrderCancelled},
+}
+
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Order is a sale recorded in StoreHub. Stock is deducted when the order is
+// created and put back if it is cancelled. Line prices are copied from the
+// product at creation time so later price changes do not alter the order.
+type Order struct {
+	ID          int64       `json:"id"`
+	Reference   string      `json:"reference"`
+	WarehouseID int64       `json:"warehouse_id"`
+	Status      OrderStatus `json:"status"`
+	Total       float64     `json:"total"`
+	Actor       string      `json:"actor"`
+	Items       []OrderItem `json:"items,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+type OrderItem struct {
+	ID        int64   `json:"id"`
+	ProductID int64   `json:"product_id"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	LineTotal float64 `json:"line_total"`
+}
+
+func (o *Order) Validate() error {
+	o.Reference = strings.TrimSpace(o.Reference)
+	o.Actor = strings.TrimSpace(o.Actor)
+	if o.WarehouseID == 0 {
+		o.WarehouseID = DefaultWarehouseID
+	}
+
+	if len(o.Reference) > 100 {
+		return errors.New("reference must be 100 characters or less")
+	}
+	if o.Actor == "" {
+		return errors.New("actor is required")
+	}
+	if len(o.Actor) > 100 {
+		return errors.New("actor must be 100 characters or less")
+	}
+	if len(o.Items) == 0 {
+		return errors.New("order needs at least one item")
+	}
+
+	seen := make(map[int64]bool, len(o.Items))
+	for _, it := range o.Items {
+		if it.ProductID <= 0 {
+			return errors.New("every item needs a product_id")
+		}
+		if seen[it.ProductID] {
+			return errors.New("each product may appear only once per order")
+		}
+		seen[it.ProductID] = true
+		if it.Quantity <= 0 {
+			return errors.New("item quantity must be positive")
+		}
+	}
+	return nil
+}
+
+type OrderStatusChange struct {
+	Actor  string `json:"actor"`
+	Reason string `json:"reason"`
+}
+
+func (c *OrderStatusChange) Validate() error {
+	c.Actor = strings.TrimSpace(c.Actor)
+	c.Reason = strings.TrimSpace(c.Reason)
+
+	if c.Actor == "" {
+		return errors.New("actor is required")
+	}
+	if len(c.Actor) > 100 {
+		return errors.New("actor must be 100 characters or less")
+	}
+	if len(c.Reason) > 255 {
+		return errors.New("reason must be 255 characters or less")
+	}
+	return nil
+}
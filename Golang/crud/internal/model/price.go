@@ -0,0 +1,69 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+type PriceSource string
+
+const (
+	PriceSourceCreate   PriceSource = "create"
+	PriceSourceUpdate   PriceSource = "update"
+	PriceSourceSchedule PriceSource = "schedule"
+)
+
+type PriceChange struct {
+	ID        int64       `json:"id"`
+	ProductID int64       `json:"product_id"`
+	Price     float64     `json:"price"`
+	Source    PriceSource `json:"source"`
+	Actor     string      `json:"actor"`
+	ChangedAt time.Time   `json:"changed_at"`
+}
+
+type ScheduledPriceStatus string
+
+const (
+	ScheduledPricePending   ScheduledPriceStatus = "pending"
+	ScheduledPriceApplied   ScheduledPriceStatus = "applied"
+	ScheduledPriceCancelled ScheduledPriceStatus = "cancelled"
+)
+
+type ScheduledPrice struct {
+	ID          int64                `json:"id"`
+	ProductID   int64                `json:"product_id"`
+	Price       float64              `json:"price"`
+	EffectiveAt time.Time            `json:"effective_at"`
+	Status      ScheduledPriceStatus `json:"status"`
+	Actor       string               `json:"actor"`
+	CreatedAt   time.Time            `json:"created_at"`
+	AppliedAt   *time.Time           `json:"applied_at"`
+}
+
+func (sp *ScheduledPrice) Validate(now time.Time) error {
+	sp.Actor = strings.TrimSpace(sp.Actor)
+
+	if sp.Price < 0 {
+		return errors.New("price must be non-negative")
+	}
+	if sp.EffectiveAt.IsZero() {
+		return errors.New("effective_at is required")
+	}
+	if !sp.EffectiveAt.After(now) {
+		return errors.New("effective_at must be in the future")
+	}
+	if sp.Actor == "" {
+		return errors.New("actor is required")
+	}
+	if len(sp.Actor) > 100 {
+		return errors.New("actor must be 100 characters or less")
+	}
+	return nil
+}
+
+type PriceOverview struct {
+	History   []PriceChange    `json:"history"`
+	Scheduled []ScheduledPrice `json:"scheduled"`
+}
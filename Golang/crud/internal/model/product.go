@@ -1,23 +1,109 @@
 package model
 
 import (
+	"encoding/json"
 	"errors"
 	"strings"
 	"time"
 )
 
+type ProductStatus string
+
+const (
+	ProductDraft        ProductStatus = "draft"
+	ProductActive       ProductStatus = "active"
+	ProductDiscontinued ProductStatus = "discontinued"
+	ProductArchived     ProductStatus = "archived"
+)
+
+var productTransitions = map[ProductStatus][]ProductStatus{
+	ProductDraft:        {ProductActive, ProductArchived},
+	ProductActive:       {ProductDiscontinued},
+	ProductDiscontinued: {ProductActive, ProductArchived},
+}
+
+func (s ProductStatus) Valid() bool {
+	switch s {
+	case ProductDraft, ProductActive, ProductDiscontinued, ProductArchived:
+		return true
+	}
+	return false
+}
+
+// Sellable reports whether products in status s can be ordered or reserved.
+func (s ProductStatus) Sellable() bool {
+	return s == ProductActive
+}
+
+func (s ProductStatus) CanTransitionTo(next ProductStatus) bool {
+	for _, allowed := range productTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Product is a catalog entry. New products start as draft or active; only
+// active products are listed by default, discontinued ones stay readable for
+// history and archived ones are left out of stats.
+//
+// A product with a ParentID is a variant of that parent (e.g. one size or
+// colour). Variants carry their own SKU and stock; their Price follows the
+// parent unless PriceOverride is set.
+//
+// StockQty is only written when a product is created, as its initial stock;
+// after that it changes through stock movements. For a bundle, StockQty and
+// AvailableQty are derived from its components.
+//
+// Attributes are typed specifications (e.g. ram: 48). When the product has a
+// category they must match its schema, which the repository checks against
+// the stored category.
 type Product struct {
-	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	StockQty    int       `json:"stock_quantity"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID            int64             `json:"id"`
+	ParentID      *int64            `json:"parent_id"`
+	SKU           string            `json:"sku"`
+	Name          string            `json:"name"`
+	Description   string            `json:"description"`
+	Options       map[string]string `json:"options,omitempty"`
+	CategoryID    *int64            `json:"category_id"`
+	Attributes    map[string]any    `json:"attributes,omitempty"`
+	Price         float64           `json:"price"`
+	PriceOverride *float64          `json:"price_override"`
+	StockQty      int               `json:"stock_quantity"`
+	ReservedQty   int               `json:"reserved_quantity"`
+	AvailableQty  int               `json:"available_quantity"`
+	ReorderPoint  int               `json:"reorder_point"`
+	ReorderQty    int               `json:"reorder_quantity"`
+	Status        ProductStatus     `json:"status"`
+	IsBundle      bool              `json:"is_bundle"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	Images        []ProductImage    `json:"images,omitempty"`
+	Variants      []Product         `json:"variants,omitempty"`
+}
+
+// DefaultReorderPoint is the reorder point of a product that does not set
+// one, as in the products table.
+const DefaultReorderPoint = 10
+
+// UnmarshalJSON applies DefaultReorderPoint when reorder_point is left out,
+// so products written through the API agree with rows the column default
+// filled in. An explicit 0 turns low-stock alerts off for the product.
+func (p *Product) UnmarshalJSON(data []byte) error {
+	type product Product
+	v := product{ReorderPoint: DefaultReorderPoint}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*p = Product(v)
+	return nil
 }
 
 func (p *Product) Validate() error {
 	p.Name = strings.TrimSpace(p.Name)
 	p.Description = strings.TrimSpace(p.Description)
+	p.SKU = strings.TrimSpace(p.SKU)
 
 	if p.Name == "" {
 		return errors.New("product name is required")
@@ -28,20 +114,74 @@ func (p *Product) Validate() error {
 	if len(p.Description) > 255 {
 		return errors.New("description must be 255 characters or less")
 	}
+	if len(p.SKU) > 64 {
+		return errors.New("sku must be 64 characters or less")
+	}
 	if p.Price < 0 {
 		return errors.New("price must be non-negative")
 	}
+	if p.PriceOverride != nil && *p.PriceOverride < 0 {
+		return errors.New("price override must be non-negative")
+	}
+	if len(p.Options) > 10 {
+		return errors.New("a product may have at most 10 options")
+	}
+	for k, v := range p.Options {
+		if strings.TrimSpace(k) == "" || len(k) > 50 {
+			return errors.New("option names must be 1 to 50 characters")
+		}
+		if len(v) > 100 {
+			return errors.New("option values must be 100 characters or less")
+		}
+	}
+	if err := validateAttributes(p.Attributes); err != nil {
+		return err
+	}
 	if p.StockQty < 0 {
 		return errors.New("stock quantity must be non-negative")
 	}
+	if p.ReorderPoint < 0 {
+		return errors.New("reorder point must be non-negative")
+	}
+	if p.ReorderQty < 0 {
+		return errors.New("reorder quantity must be non-negative")
+	}
+	if p.Status != "" && !p.Status.Valid() {
+		return errors.New("status must be one of draft, active, discontinued, archived")
+	}
 	return nil
 }
 
 type Stats struct {
-	TotalProducts int     `json:"total_products"`
-	TotalStock    int     `json:"total_stock"`
-	TotalValue    float64 `json:"total_value"`
-	LowStockCount int    `json:"low_stock_count"`
+	TotalProducts int              `json:"total_products"`
+	TotalStock    int              `json:"total_stock"`
+	TotalValue    float64          `json:"total_value"`
+	LowStockCount int              `json:"low_stock_count"`
+	Warehouses    []WarehouseStats `json:"warehouses"`
+}
+
+// ReorderSuggestion is a product at or below its reorder point together with
+// how many units to order: the configured reorder quantity, or enough to lift
+// stock back above the reorder point if that is larger.
+type ReorderSuggestion struct {
+	ProductID    int64  `json:"product_id"`
+	Name         string `json:"name"`
+	StockQty     int    `json:"stock_quantity"`
+	ReservedQty  int    `json:"reserved_quantity"`
+	ReorderPoint int    `json:"reorder_point"`
+	ReorderQty   int    `json:"reorder_quantity"`
+	SuggestedQty int    `json:"suggested_quantity"`
+}
+
+// ProductFilter narrows a product listing. An empty Status matches every
+// status and a zero CategoryID every category. Grouped lists only top-level
+// products, each with its variants.
+type ProductFilter struct {
+	Search     string
+	Status     ProductStatus
+	Grouped    bool
+	CategoryID int64
+	Attributes []AttributeFilter
 }
 
 type PaginatedResponse struct {
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestProductStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to ProductStatus
+		want     bool
+	}{
+		{ProductDraft, ProductActive, true},
+		{ProductDraft, ProductArchived, true},
+		{ProductDraft, ProductDiscontinued, false},
+		{ProductActive, ProductDiscontinued, true},
+		{ProductActive, ProductDraft, false},
+		{ProductActive, ProductArchived, false},
+		{ProductDiscontinued, ProductActive, true},
+		{ProductDiscontinued, ProductArchived, true},
+		{ProductArchived, ProductActive, false},
+		{ProductArchived, ProductDraft, false},
+		{ProductActive, ProductActive, false},
+		{ProductStatus("deleted"), ProductActive, false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%s -> %s: got %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestProductStatusSellable(t *testing.T) {
+	for _, s := range []ProductStatus{ProductDraft, ProductActive, ProductDiscontinued, ProductArchived} {
+		if got, want := s.Sellable(), s == ProductActive; got != want {
+			t.Errorf("%s: sellable %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestProductValidate(t *testing.T) {
+	neg := -1.0
+	tests := []struct {
+		name    string
+		product Product
+		wantErr string
+	}{
+		{"minimal", Product{Name: "Widget"}, ""},
+		{"every status", Product{Name: "Widget", Status: ProductArchived}, ""},
+		{"blank name", Product{Name: "   "}, "name is required"},
+		{"long name", Product{Name: strings.Repeat("x", 101)}, "100 characters"},
+		{"long description", Product{Name: "Widget", Description: strings.Repeat("x", 256)}, "255 characters"},
+		{"long sku", Product{Name: "Widget", SKU: strings.Repeat("x", 65)}, "64 characters"},
+		{"negative price", Product{Name: "Widget", Price: -1}, "price must be non-negative"},
+		{"negative override", Product{Name: "Widget", PriceOverride: &neg}, "override must be non-negative"},
+		{"blank option name", Product{Name: "Widget", Options: map[string]string{" ": "red"}}, "option names"},
+		{"negative stock", Product{Name: "Widget", StockQty: -1}, "stock quantity"},
+		{"negative reorder point", Product{Name: "Widget", ReorderPoint: -1}, "reorder point"},
+		{"negative reorder quantity", Product{Name: "Widget", ReorderQty: -1}, "reorder quantity"},
+		{"unknown status", Product{Name: "Widget", Status: "deleted"}, "status must be one of"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.product.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Fatalf("got no error, want one containing %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Fatalf("got error %q, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProductValidateTrims(t *testing.T) {
+	p := Product{Name: "  Widget ", SKU: " W-1 ", Description: " small "}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Widget" || p.SKU != "W-1" || p.Description != "small" {
+		t.Errorf("got %q %q %q, want fields trimmed", p.Name, p.SKU, p.Description)
+	}
+}
+
+func TestProductUnmarshalReorderPoint(t *testing.T) {
+	tests := []struct {
+		body string
+		want int
+	}{
+		{`{"name":"Widget"}`, DefaultReorderPoint},
+		{`{"name":"Widget","reorder_point":0}`, 0},
+		{`{"name":"Widget","reorder_point":25}`, 25},
+	}
+	for _, tt := range tests {
+		var p Product
+		if err := json.Unmarshal([]byte(tt.body), &p); err != nil {
+			t.Fatalf("%s: %v", tt.body, err)
+		}
+		if p.ReorderPoint != tt.want || p.Name != "Widget" {
+			t.Errorf("%s: reorder point %d, name %q; want %d", tt.body, p.ReorderPoint, p.Name, tt.want)
+		}
+	}
+}
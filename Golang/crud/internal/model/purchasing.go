@@ -0,0 +1,152 @@
+package model
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+type Supplier struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (s *Supplier) Validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Email = strings.TrimSpace(s.Email)
+	s.Phone = strings.TrimSpace(s.Phone)
+
+	if s.Name == "" {
+		return errors.New("supplier name is required")
+	}
+	if len(s.Name) > 100 {
+		return errors.New("supplier name must be 100 characters or less")
+	}
+	if s.Email != "" {
+		if _, err := mail.ParseAddress(s.Email); err != nil || len(s.Email) > 255 {
+			return errors.New("supplier email is invalid")
+		}
+	}
+	if len(s.Phone) > 30 {
+		return errors.New("supplier phone must be 30 characters or less")
+	}
+	return nil
+}
+
+type PurchaseOrderStatus string
+
+const (
+	PODraft             PurchaseOrderStatus = "draft"
+	POSent              PurchaseOrderStatus = "sent"
+	POPartiallyReceived PurchaseOrderStatus = "partially_received"
+	POReceived          PurchaseOrderStatus = "received"
+	POCancelled         PurchaseOrderStatus = "cancelled"
+)
+
+var poTransitions = map[PurchaseOrderStatus][]PurchaseOrderStatus{
+	PODraft:             {POSent, POCancelled},
+	POSent:              {POPartiallyReceived, POReceived, POCancelled},
+	POPartiallyReceived: {POPartiallyReceived, POReceived},
+}
+
+func (s PurchaseOrderStatus) CanTransitionTo(next PurchaseOrderStatus) bool {
+	for _, allowed := range poTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+type PurchaseOrder struct {
+	ID          int64               `json:"id"`
+	SupplierID  int64               `json:"supplier_id"`
+	WarehouseID int64               `json:"warehouse_id"`
+	Status      PurchaseOrderStatus `json:"status"`
+	Notes       string              `json:"notes"`
+	Items       []PurchaseOrderItem `json:"items"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+type PurchaseOrderItem struct {
+	ID               int64   `json:"id"`
+	ProductID        int64   `json:"product_id"`
+	QuantityOrdered  int     `json:"quantity_ordered"`
+	QuantityReceived int     `json:"quantity_received"`
+	UnitCost         float64 `json:"unit_cost"`
+}
+
+func (po *PurchaseOrder) Validate() error {
+	po.Notes = strings.TrimSpace(po.Notes)
+	if po.WarehouseID == 0 {
+		po.WarehouseID = DefaultWarehouseID
+	}
+
+	if po.SupplierID <= 0 {
+		return errors.New("supplier_id is required")
+	}
+	if len(po.Notes) > 255 {
+		return errors.New("notes must be 255 characters or less")
+	}
+	if len(po.Items) == 0 {
+		return errors.New("purchase order needs at least one item")
+	}
+
+	seen := make(map[int64]bool, len(po.Items))
+	for _, it := range po.Items {
+		if it.ProductID <= 0 {
+			return errors.New("every item needs a product_id")
+		}
+		if seen[it.ProductID] {
+			return errors.New("each product may appear only once per purchase order")
+		}
+		seen[it.ProductID] = true
+		if it.QuantityOrdered <= 0 {
+			return errors.New("quantity_ordered must be positive")
+		}
+		if it.UnitCost < 0 {
+			return errors.New("unit_cost must be non-negative")
+		}
+	}
+	return nil
+}
+
+// PurchaseOrderReceipt books goods arriving against a sent purchase order.
+// Each line names the product and how many units arrived.
+type PurchaseOrderReceipt struct {
+	Actor string                     `json:"actor"`
+	Lines []PurchaseOrderReceiptLine `json:"lines"`
+}
+
+type PurchaseOrderReceiptLine struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int   `json:"quantity"`
+}
+
+func (r *PurchaseOrderReceipt) Validate() error {
+	r.Actor = strings.TrimSpace(r.Actor)
+
+	if r.Actor == "" {
+		return errors.New("actor is required")
+	}
+	if len(r.Actor) > 100 {
+		return errors.New("actor must be 100 characters or less")
+	}
+	if len(r.Lines) == 0 {
+		return errors.New("receipt needs at least one line")
+	}
+	for _, l := range r.Lines {
+		if l.ProductID <= 0 {
+			return errors.New("every line needs a product_id")
+		}
+		if l.Quantity <= 0 {
+			return errors.New("received quantity must be positive")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationCommitted ReservationStatus = "committed"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationExpired   ReservationStatus = "expired"
+)
+
+type Reservation struct {
+	ID         int64             `json:"id"`
+	ProductID  int64             `json:"product_id"`
+	Quantity   int               `json:"quantity"`
+	TTLSeconds int               `json:"ttl_seconds,omitempty"`
+	Status     ReservationStatus `json:"status"`
+	ExpiresAt  time.Time         `json:"expires_at"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+func (r *Reservation) Validate(maxTTL time.Duration) error {
+	if r.ProductID <= 0 {
+		return errors.New("product_id is required")
+	}
+	if r.Quantity <= 0 {
+		return errors.New("quantity must be positive")
+	}
+	if r.TTLSeconds < 0 {
+		return errors.New("ttl_seconds must be non-negative")
+	}
+	if time.Duration(r.TTLSeconds)*time.Second > maxTTL {
+		return errors.New("ttl_seconds must be " + maxTTL.String() + " or less")
+	}
+	return nil
+}
+
+type ReservationCommit struct {
+	WarehouseID int64  `json:"warehouse_id"`
+	Reason      string `json:"reason"`
+	Actor       string `json:"actor"`
+}
+
+func (c *ReservationCommit) Validate() error {
+	c.Reason = strings.TrimSpace(c.Reason)
+	c.Actor = strings.TrimSpace(c.Actor)
+	if c.WarehouseID == 0 {
+		c.WarehouseID = DefaultWarehouseID
+	}
+
+	if c.Actor == "" {
+		return errors.New("actor is required")
+	}
+	if len(c.Actor) > 100 {
+		return errors.New("actor must be 100 characters or less")
+	}
+	if len(c.Reason) > 255 {
+		return errors.New("reason must be 255 characters or less")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+type MovementType string
+
+const (
+	MovementReceipt    MovementType = "receipt"
+	MovementSale       MovementType = "sale"
+	MovementAdjustment MovementType = "adjustment"
+	MovementReturn     MovementType = "return"
+	MovementTransfer   MovementType = "transfer"
+)
+
+// StockMovement is a single ledger entry. Quantity is the signed change it
+// applied to the product's stock at WarehouseID; StockAfter is the resulting
+// on-hand count across all warehouses.
+type StockMovement struct {
+	ID          int64        `json:"id"`
+	ProductID   int64        `json:"product_id"`
+	WarehouseID int64        `json:"warehouse_id"`
+	Type        MovementType `json:"type"`
+	Quantity    int          `json:"quantity"`
+	StockAfter  int          `json:"stock_after"`
+	Reason      string       `json:"reason"`
+	Actor       string       `json:"actor"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+func (m *StockMovement) Validate() error {
+	m.Reason = strings.TrimSpace(m.Reason)
+	m.Actor = strings.TrimSpace(m.Actor)
+	if m.WarehouseID == 0 {
+		m.WarehouseID = DefaultWarehouseID
+	}
+
+	switch m.Type {
+	case MovementReceipt, MovementReturn:
+		if m.Quantity <= 0 {
+			return errors.New("quantity must be positive for " + string(m.Type))
+		}
+	case MovementSale:
+		if m.Quantity >= 0 {
+			return errors.New("quantity must be negative for sale")
+		}
+	case MovementAdjustment:
+		if m.Quantity == 0 {
+			return errors.New("adjustment quantity must be non-zero")
+		}
+		if m.Reason == "" {
+			return errors.New("reason is required for adjustment")
+		}
+	default:
+		return errors.New("movement type must be one of receipt, sale, adjustment, return")
+	}
+
+	if m.Actor == "" {
+		return errors.New("actor is required")
+	}
+	if len(m.Actor) > 100 {
+		return errors.New("actor must be 100 characters or less")
+	}
+	if len(m.Reason) > 255 {
+		return errors.New("reason must be 255 characters or less")
+	}
+	return nil
+}
+
+// StockAdjustment is a relative change to on-hand stock, applied with a single
+// conditional UPDATE so concurrent callers can never take away stock that is
+// not there or is held by a reservation.
+type StockAdjustment struct {
+	WarehouseID int64  `json:"warehouse_id"`
+	Delta       int    `json:"delta"`
+	Reason      string `json:"reason"`
+	Actor       string `json:"actor"`
+}
+
+func (a *StockAdjustment) Validate() error {
+	a.Reason = strings.TrimSpace(a.Reason)
+	a.Actor = strings.TrimSpace(a.Actor)
+	if a.WarehouseID == 0 {
+		a.WarehouseID = DefaultWarehouseID
+	}
+
+	if a.Delta == 0 {
+		return errors.New("delta must be non-zero")
+	}
+	if a.Actor == "" {
+		return errors.New("actor is required")
+	}
+	if len(a.Actor) > 100 {
+		return errors.New("actor must be 100 characters or less")
+	}
+	if len(a.Reason) > 255 {
+		return errors.New("reason must be 255 characters or less")
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// DefaultWarehouseID is the location created by the warehouse migration. Stock
+// changes that do not name a location are applied here.
+const DefaultWarehouseID int64 = 1
+
+type Warehouse struct {
+	ID        int64     `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (w *Warehouse) Validate() error {
+	w.Code = strings.ToUpper(strings.TrimSpace(w.Code))
+	w.Name = strings.TrimSpace(w.Name)
+
+	if w.Code == "" {
+		return errors.New("warehouse code is required")
+	}
+	if len(w.Code) > 20 {
+		return errors.New("warehouse code must be 20 characters or less")
+	}
+	if w.Name == "" {
+		return errors.New("warehouse name is required")
+	}
+	if len(w.Name) > 100 {
+		return errors.New("warehouse name must be 100 characters or less")
+	}
+	return nil
+}
+
+type StockLevel struct {
+	WarehouseID   int64  `json:"warehouse_id"`
+	WarehouseCode string `json:"warehouse_code"`
+	ProductID     int64  `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	Quantity      int    `json:"quantity"`
+}
+
+type StockTransfer struct {
+	ProductID       int64  `json:"product_id"`
+	FromWarehouseID int64  `json:"from_warehouse_id"`
+	ToWarehouseID   int64  `json:"to_warehouse_id"`
+	Quantity        int    `json:"quantity"`
+	Reason          string `json:"reason"`
+	Actor           string `json:"actor"`
+}
+
+func (t *StockTransfer) Validate() error {
+	t.Reason = strings.TrimSpace(t.Reason)
+	t.Actor = strings.TrimSpace(t.Actor)
+
+	if t.ProductID <= 0 {
+		return errors.New("product_id is required")
+	}
+	if t.FromWarehouseID <= 0 || t.ToWarehouseID <= 0 {
+		return errors.New("from_warehouse_id and to_warehouse_id are required")
+	}
+	if t.FromWarehouseID == t.ToWarehouseID {
+		return errors.New("source and destination warehouse must differ")
+	}
+	if t.Quantity <= 0 {
+		return errors.New("quantity must be positive")
+	}
+	if t.Actor == "" {
+		return errors.New("actor is required")
+	}
+	if len(t.Actor) > 100 {
+		return errors.New("actor must be 100 characters or less")
+	}
+	if len(t.Reason) > 255 {
+		return errors.New("reason must be 255 characters or less")
+	}
+	return nil
+}
+
+type WarehouseStats struct {
+	WarehouseID   int64   `json:"warehouse_id"`
+	Code          string  `json:"code"`
+	Name          string  `json:"name"`
+	TotalProducts int     `json:"total_products"`
+	TotalStock    int     `json:"total_stock"`
+	TotalValue    float64 `json:"total_value"`
+}
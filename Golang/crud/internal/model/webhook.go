@@ -0,0 +1,95 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// WebhookSubscription asks for events of the listed types to be POSTed to
+// URL, signed with Secret. The secret is only ever returned when the
+// subscription is created.
+type WebhookSubscription struct {
+	ID        int64       `json:"id"`
+	URL       string      `json:"url"`
+	Events    []EventType `json:"events"`
+	Secret    string      `json:"secret,omitempty"`
+	Active    bool        `json:"active"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+func (s *WebhookSubscription) Validate() error {
+	s.URL = strings.TrimSpace(s.URL)
+	s.Secret = strings.TrimSpace(s.Secret)
+
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	if len(s.URL) > 500 {
+		return errors.New("url must be 500 characters or less")
+	}
+	if len(s.Events) == 0 {
+		return errors.New("subscription needs at least one event type")
+	}
+	for _, e := range s.Events {
+		if !slices.Contains(EventTypes, e) {
+			return fmt.Errorf("unknown event type %q", e)
+		}
+	}
+	slices.Sort(s.Events)
+	s.Events = slices.Compact(s.Events)
+	if s.Secret != "" && (len(s.Secret) < 16 || len(s.Secret) > 100) {
+		return errors.New("secret must be between 16 and 100 characters")
+	}
+	return nil
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	DeliveryDead      WebhookDeliveryStatus = "dead"
+)
+
+func (s WebhookDeliveryStatus) Valid() bool {
+	switch s {
+	case DeliveryPending, DeliverySucceeded, DeliveryDead:
+		return true
+	}
+	return false
+}
+
+// WebhookDelivery is one event on its way to one subscription. A pending
+// delivery with attempts has failed that many times and waits until
+// NextAttemptAt; a dead one has run out of attempts.
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	SubscriptionID int64                 `json:"subscription_id"`
+	EventID        int64                 `json:"event_id"`
+	EventType      EventType             `json:"event_type"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	LastError      string                `json:"last_error,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+}
+
+// WebhookAttempt is a delivery claimed for sending, with what is needed to
+// send it.
+type WebhookAttempt struct {
+	DeliveryID int64
+	EventID    int64
+	EventType  EventType
+	Attempt    int
+	URL        string
+	Secret     string
+	Payload    []byte
+}
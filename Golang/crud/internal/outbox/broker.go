@@ -0,0 +1,41 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"golang-sql/internal/model"
+)
+
+// Publisher is the one call the Broker sink needs from a message broker
+// client. A thin adapter over a NATS connection (subject) or a Kafka writer
+// (topic, message key) satisfies it; the client must wait for the broker to
+// acknowledge the message before returning.
+type Publisher interface {
+	Publish(ctx context.Context, subject, key string, data []byte) error
+}
+
+// Broker is a Sink that publishes each event as JSON to prefix plus its type,
+// e.g. "storehub.product.updated", keyed by product ID so a partitioned
+// broker keeps each product's events in order.
+type Broker struct {
+	pub    Publisher
+	prefix string
+}
+
+func NewBroker(pub Publisher, prefix string) *Broker {
+	return &Broker{pub: pub, prefix: prefix}
+}
+
+func (s *Broker) Publish(ctx context.Context, e model.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.pub.Publish(ctx, s.prefix+string(e.Type), strconv.FormatInt(e.ProductID, 10), data)
+}
+
+func (s *Broker) Close() error {
+	return nil
+}
@@ -0,0 +1,83 @@
+// Package outbox publishes the product change events that the repository
+// layer records in its outbox table.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+
+	"golang-sql/internal/model"
+)
+
+// Sink is where the relay publishes events. Publish must not return until
+// the event is durably handed over, since a nil error marks it published.
+type Sink interface {
+	Publish(ctx context.Context, e model.Event) error
+	Close() error
+}
+
+// Writer is a Sink that writes each event as a line of JSON.
+type Writer struct {
+	mu   sync.Mutex
+	enc  *json.Encoder
+	file *os.File
+}
+
+// NewStdout writes events to standard output.
+func NewStdout() *Writer {
+	return &Writer{enc: json.NewEncoder(os.Stdout)}
+}
+
+// NewFile appends events to the file at path, creating it if needed. Each
+// event is synced to disk before it counts as published.
+func NewFile(path string) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{enc: json.NewEncoder(f), file: f}, nil
+}
+
+func (s *Writer) Publish(_ context.Context, e model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.enc.Encode(e); err != nil {
+		return err
+	}
+	if s.file != nil {
+		return s.file.Sync()
+	}
+	return nil
+}
+
+func (s *Writer) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
+
+// Fanout is a Sink that publishes each event to every one of sinks in turn,
+// stopping at the first failure. The event is then retried from the first
+// sink, so every sink must cope with receiving it again.
+type Fanout []Sink
+
+func (s Fanout) Publish(ctx context.Context, e model.Event) error {
+	for _, sink := range s {
+		if err := sink.Publish(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s Fanout) Close() error {
+	var errs []error
+	for _, sink := range s {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang-sql/internal/model"
+)
+
+// saveAttributes replaces the stored attributes of a product, first checking
+// them against the schema of its category if it has one.
+func saveAttributes(ctx context.Context, tx *sql.Tx, productID int64, categoryID *int64, attrs map[string]any) error {
+	if categoryID != nil {
+		c, err := getCategory(ctx, tx, *categoryID, true)
+		if err != nil {
+			return err
+		}
+		if err := c.CheckAttributes(attrs); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidAttributes, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_attributes WHERE product_id = ?", productID); err != nil {
+		return fmt.Errorf("clearing attributes of product %d: %w", productID, err)
+	}
+
+	for name, v := range attrs {
+		var (
+			valueType string
+			value     string
+			numValue  any
+		)
+		switch v := v.(type) {
+		case float64:
+			valueType, value, numValue = "number", strconv.FormatFloat(v, 'f', -1, 64), v
+		case bool:
+			valueType, value = "boolean", strconv.FormatBool(v)
+		case string:
+			valueType, value = "string", v
+		default:
+			return fmt.Errorf("%w: value of %s must be a string, number or boolean", ErrInvalidAttributes, name)
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO product_attributes (product_id, name, value_type, value, num_value)
+			 VALUES (?, ?, ?, ?, ?)`,
+			productID, name, valueType, value, numValue,
+		); err != nil {
+			return fmt.Errorf("saving attribute %s of product %d: %w", name, productID, err)
+		}
+	}
+	return nil
+}
+
+// attributeConditions turns attribute filters into SQL conditions on the
+// products table, one EXISTS per filter.
+func attributeConditions(filters []model.AttributeFilter) ([]string, []interface{}) {
+	conds := make([]string, 0, len(filters))
+	args := make([]interface{}, 0, 2*len(filters))
+	for _, f := range filters {
+		cmp := "pa.value = ?"
+		switch f.Op {
+		case model.AttrGte:
+			cmp = "pa.num_value >= ?"
+		case model.AttrLte:
+			cmp = "pa.num_value <= ?"
+		}
+		conds = append(conds, `EXISTS (SELECT 1 FROM product_attributes pa
+			WHERE pa.product_id = products.product_id AND pa.name = ? AND `+cmp+`)`)
+		args = append(args, f.Name, f.Value)
+	}
+	return conds, args
+}
+
+// attachDetails loads the images and attributes of every product in products.
+func attachDetails(ctx context.Context, q queryer, products []model.Product) error {
+	if err := attachImages(ctx, q, products); err != nil {
+		return err
+	}
+	return attachAttributes(ctx, q, products)
+}
+
+func attachAttributes(ctx context.Context, q queryer, products []model.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	index := make(map[int64]int, len(products))
+	args := make([]interface{}, 0, len(products))
+	for i, p := range products {
+		index[p.ID] = i
+		args = append(args, p.ID)
+	}
+
+	rows, err := q.QueryContext(ctx,
+		`SELECT product_id, name, value_type, value FROM product_attributes
+		 WHERE product_id IN (?`+strings.Repeat(", ?", len(products)-1)+`)`, args...)
+	if err != nil {
+		return fmt.Errorf("listing product attributes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			productID         int64
+			name, typ, stored string
+		)
+		if err := rows.Scan(&productID, &name, &typ, &stored); err != nil {
+			return fmt.Errorf("scanning product attribute row: %w", err)
+		}
+
+		var v any = stored
+		switch typ {
+		case "number":
+			if f, err := strconv.ParseFloat(stored, 64); err == nil {
+				v = f
+			}
+		case "boolean":
+			v = stored == "true"
+		}
+
+		p := &products[index[productID]]
+		if p.Attributes == nil {
+			p.Attributes = make(map[string]any)
+		}
+		p.Attributes[name] = v
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating product attribute rows: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"golang-sql/internal/model"
+)
+
+var ErrBatchRolledBack = errors.New("rolled back with the rest of the batch")
+
+// Batch applies ops in order and returns one error per operation, nil for
+// those that took effect. An atomic batch runs in a single transaction that
+// is abandoned at the first failure, leaving every other operation with
+// ErrBatchRolledBack. Otherwise each operation runs in its own transaction
+// and a failure affects only that operation. The second return value is
+// reserved for failures of the batch as a whole.
+func (r *mysqlProductRepo) Batch(ctx context.Context, ops []model.BatchOperation, atomic bool) ([]error, error) {
+	errs := make([]error, len(ops))
+	if !atomic {
+		for i := range ops {
+			errs[i] = r.applyInTx(ctx, &ops[i])
+		}
+		return errs, nil
+	}
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("beginning batch: %w", err)
+	}
+	defer tx.Rollback()
+
+	ids := make([]int64, len(ops))
+	for i := range ops {
+		id, err := r.applyOperation(ctx, tx.Tx, &ops[i])
+		if err != nil {
+			for j := range errs {
+				errs[j] = ErrBatchRolledBack
+			}
+			errs[i] = err
+			return errs, nil
+		}
+		ids[i] = id
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing batch: %w", err)
+	}
+	for i, op := range ops {
+		if op.Action == model.BatchCreate {
+			op.Product.ID = ids[i]
+		}
+	}
+	return errs, nil
+}
+
+func (r *mysqlProductRepo) applyInTx(ctx context.Context, op *model.BatchOperation) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning batch operation: %w", err)
+	}
+	defer tx.Rollback()
+
+	id, err := r.applyOperation(ctx, tx.Tx, op)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing batch operation: %w", err)
+	}
+	if op.Action == model.BatchCreate {
+		op.Product.ID = id
+	}
+	return nil
+}
+
+// applyOperation runs one batch operation inside tx, returning the ID of the
+// product it created, if any.
+func (r *mysqlProductRepo) applyOperation(ctx context.Context, tx *sql.Tx, op *model.BatchOperation) (int64, error) {
+	switch op.Action {
+	case model.BatchCreate:
+		return r.createProduct(ctx, tx, op.Product)
+	case model.BatchUpdate:
+		return 0, r.updateProduct(ctx, tx, op.Product)
+	case model.BatchDelete:
+		return 0, r.deleteProduct(ctx, tx, op.ID)
+	default:
+		return 0, fmt.Errorf("unknown batch action %q", op.Action)
+	}
+}
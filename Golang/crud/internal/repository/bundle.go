@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang-sql/internal/model"
+)
+
+var (
+	ErrBundleStock      = errors.New("bundles hold no stock of their own")
+	ErrInvalidComponent = errors.New("bundle components must be plain products")
+)
+
+// bundleAvailability is the number of complete kits the components of the
+// bundle in the enclosing products row can make. It is correlated on
+// products.product_id, so it may only be used inside a query on products.
+const bundleAvailability = `SELECT COALESCE(MIN(GREATEST(c.stock_quantity - c.reserved_quantity, 0) DIV bc.quantity), 0)
+	FROM bundle_components bc JOIN products c ON c.product_id = bc.component_id
+	WHERE bc.bundle_id = products.product_id`
+
+// bundleUpdatedAt is when the bundle in the enclosing products row or any of
+// its components last changed, since a component's stock changes the
+// bundle's availability. Like bundleAvailability it may only be used inside a
+// query on products.
+const bundleUpdatedAt = `SELECT MAX(c.updated_at)
+	FROM bundle_components bc JOIN products c ON c.product_id = bc.component_id
+	WHERE bc.bundle_id = products.product_id`
+
+func (r *mysqlProductRepo) GetBundle(ctx context.Context, id int64) (*model.Bundle, error) {
+	b := &model.Bundle{ProductID: id, Components: []model.BundleComponent{}}
+
+	var isBundle bool
+	err := r.conn().QueryRowContext(ctx,
+		"SELECT is_bundle, IF(is_bundle, ("+bundleAvailability+"), 0) FROM products WHERE product_id = ?", id,
+	).Scan(&isBundle, &b.AvailableQty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("product %d: %w", id, ErrProductNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting bundle %d: %w", id, err)
+	}
+	if !isBundle {
+		return b, nil
+	}
+
+	rows, err := r.conn().QueryContext(ctx,
+		`SELECT bc.component_id, c.name, bc.quantity, c.stock_quantity - c.reserved_quantity
+		 FROM bundle_components bc JOIN products c ON c.product_id = bc.component_id
+		 WHERE bc.bundle_id = ? ORDER BY bc.component_id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("listing components of bundle %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c model.BundleComponent
+		if err := rows.Scan(&c.ComponentID, &c.Name, &c.Quantity, &c.AvailableQty); err != nil {
+			return nil, fmt.Errorf("scanning bundle component row: %w", err)
+		}
+		b.Components = append(b.Components, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating bundle component rows: %w", err)
+	}
+	return b, nil
+}
+
+// SetBundle replaces the components of b.ProductID. A product can only become
+// a bundle while it holds no stock, and bundles cannot be nested. An empty
+// component list turns the bundle back into a plain product.
+func (r *mysqlProductRepo) SetBundle(ctx context.Context, b *model.Bundle) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning bundle update: %w", err)
+	}
+	defer tx.Rollback()
+
+	var stock, reserved int
+	err = tx.QueryRowContext(ctx,
+		"SELECT stock_quantity, reserved_quantity FROM products WHERE product_id = ? FOR UPDATE", b.ProductID,
+	).Scan(&stock, &reserved)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("product %d: %w", b.ProductID, ErrProductNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("locking product %d: %w", b.ProductID, err)
+	}
+	if len(b.Components) > 0 && (stock != 0 || reserved != 0) {
+		return fmt.Errorf("product %d holds %d units: %w", b.ProductID, stock, ErrBundleStock)
+	}
+
+	var usedAsComponent int
+	if err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM bundle_components WHERE component_id = ?", b.ProductID,
+	).Scan(&usedAsComponent); err != nil {
+		return fmt.Errorf("checking bundles using product %d: %w", b.ProductID, err)
+	}
+	if len(b.Components) > 0 && usedAsComponent > 0 {
+		return fmt.Errorf("product %d is a component of another bundle: %w", b.ProductID, ErrInvalidComponent)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM bundle_components WHERE bundle_id = ?", b.ProductID); err != nil {
+		return fmt.Errorf("clearing components of bundle %d: %w", b.ProductID, err)
+	}
+
+	for _, c := range b.Components {
+		var componentIsBundle bool
+		err := tx.QueryRowContext(ctx,
+			"SELECT is_bundle FROM products WHERE product_id = ? FOR SHARE", c.ComponentID,
+		).Scan(&componentIsBundle)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("component %d: %w", c.ComponentID, ErrInvalidComponent)
+		}
+		if err != nil {
+			return fmt.Errorf("checking component %d: %w", c.ComponentID, err)
+		}
+		if componentIsBundle {
+			return fmt.Errorf("component %d is a bundle: %w", c.ComponentID, ErrInvalidComponent)
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO bundle_components (bundle_id, component_id, quantity) VALUES (?, ?, ?)",
+			b.ProductID, c.ComponentID, c.Quantity,
+		); err != nil {
+			return fmt.Errorf("adding component %d to bundle %d: %w", c.ComponentID, b.ProductID, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE products SET is_bundle = ?, updated_at = CURRENT_TIMESTAMP(6) WHERE product_id = ?",
+		len(b.Components) > 0, b.ProductID,
+	); err != nil {
+		return fmt.Errorf("updating product %d: %w", b.ProductID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing bundle update: %w", err)
+	}
+	return nil
+}
+
+// recordBundleMovement books a sale or return of a bundle as one movement per
+// component. The bundle has no stock of its own, so nothing is written to its
+// ledger and m is left without an ID.
+func recordBundleMovement(ctx context.Context, tx *sql.Tx, m *model.StockMovement) error {
+	if m.Type != model.MovementSale && m.Type != model.MovementReturn {
+		return fmt.Errorf("%s of bundle %d: %w", m.Type, m.ProductID, ErrBundleStock)
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT component_id, quantity FROM bundle_components WHERE bundle_id = ? ORDER BY component_id",
+		m.ProductID,
+	)
+	if err != nil {
+		return fmt.Errorf("listing components of bundle %d: %w", m.ProductID, err)
+	}
+	var components []model.BundleComponent
+	for rows.Next() {
+		var c model.BundleComponent
+		if err := rows.Scan(&c.ComponentID, &c.Quantity); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning bundle component row: %w", err)
+		}
+		components = append(components, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating bundle component rows: %w", err)
+	}
+
+	reason := fmt.Sprintf("bundle %d", m.ProductID)
+	if m.Reason != "" {
+		reason += ": " + m.Reason
+	}
+	if len(reason) > 255 {
+		reason = strings.ToValidUTF8(reason[:255], "")
+	}
+
+	for _, c := range components {
+		cm := &model.StockMovement{
+			ProductID:   c.ComponentID,
+			WarehouseID: m.WarehouseID,
+			Type:        m.Type,
+			Quantity:    m.Quantity * c.Quantity,
+			Reason:      reason,
+			Actor:       m.Actor,
+		}
+		if err := recordMovement(ctx, tx, cm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"golang-sql/internal/cache"
+	"golang-sql/internal/model"
+)
+
+const statsKey = "stats"
+
+func productKey(id int64) string {
+	return "product:" + strconv.FormatInt(id, 10)
+}
+
+// cachedProductRepo serves GetByID and Stats from a cache.Store in front of
+// another ProductRepository. Concurrent misses for the same key share one
+// database read, and writes made through this repository drop the entries
+// they affect once they have committed.
+//
+// Misses are loaded from the primary database rather than a replica, so an
+// entry filled just after an invalidation cannot bring back what a lagging
+// replica still holds.
+//
+// Changes made elsewhere (orders, reservations, receipts, scheduled prices,
+// image uploads) and values derived from other products, such as a bundle's
+// availability, are only picked up when the entry expires, so the TTL bounds
+// how stale a read can be. Reads marked with ReadPrimary, which must see the
+// latest committed value, bypass the cache.
+type cachedProductRepo struct {
+	ProductRepository
+	store  cache.Store
+	ttl    time.Duration
+	logger *slog.Logger
+	group  singleflight.Group
+
+	// epoch counts invalidations. A read that straddles one does not store
+	// its result, since it may predate the write; mu makes the check and the
+	// store atomic with respect to the increment.
+	mu    sync.RWMutex
+	epoch atomic.Uint64
+
+	// pending is set on the repository handed to a WithTx callback. Reads
+	// bypass the cache, since they must see the transaction's own writes, and
+	// invalidations are collected until the transaction has finished.
+	pending *pendingInvalidation
+}
+
+type pendingInvalidation struct {
+	ids   []int64
+	dirty bool
+}
+
+func NewCachedProductRepo(repo ProductRepository, store cache.Store, ttl time.Duration, logger *slog.Logger) ProductRepository {
+	return &cachedProductRepo{ProductRepository: repo, store: store, ttl: ttl, logger: logger}
+}
+
+func (r *cachedProductRepo) GetByID(ctx context.Context, id int64) (*model.Product, error) {
+	if r.pending != nil {
+		return r.ProductRepository.GetByID(ctx, id)
+	}
+	return readThrough(ctx, r, productKey(id), func(ctx context.Context) (*model.Product, error) {
+		return r.ProductRepository.GetByID(ctx, id)
+	})
+}
+
+func (r *cachedProductRepo) Stats(ctx context.Context) (*model.Stats, error) {
+	if r.pending != nil {
+		return r.ProductRepository.Stats(ctx)
+	}
+	return readThrough(ctx, r, statsKey, r.ProductRepository.Stats)
+}
+
+// readThrough returns the value under key, loading and storing it on a miss.
+// Every caller decodes its own copy, so callers never share a value. A nil
+// result (e.g. a product that does not exist) is not cached.
+func readThrough[T any](ctx context.Context, r *cachedProductRepo, key string, load func(context.Context) (*T, error)) (*T, error) {
+	if readsPrimary(ctx) {
+		return load(ctx)
+	}
+	b, ok, err := r.store.Get(ctx, key)
+	if err != nil {
+		r.logger.Warn("cache_get_failed", slog.String("key", key), slog.String("error", err.Error()))
+	}
+	if !ok {
+		ch := r.group.DoChan(key, func() (any, error) {
+			epoch := r.epoch.Load()
+			// The read is shared with other callers, so it must not fail
+			// because the one that started it went away.
+			v, err := load(ReadPrimary(context.WithoutCancel(ctx)))
+			if err != nil || v == nil {
+				return []byte(nil), err
+			}
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			if r.epoch.Load() == epoch {
+				if err := r.store.Set(context.WithoutCancel(ctx), key, b, r.ttl); err != nil {
+					r.logger.Warn("cache_set_failed", slog.String("key", key), slog.String("error", err.Error()))
+				}
+			}
+			return b, nil
+		})
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case res := <-ch:
+			if res.Err != nil {
+				return nil, res.Err
+			}
+			b = res.Val.([]byte)
+		}
+	}
+	if b == nil {
+		return nil, nil
+	}
+
+	v := new(T)
+	if err := json.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// invalidate drops the cached products with the given IDs and the stats.
+func (r *cachedProductRepo) invalidate(ctx context.Context, ids ...int64) {
+	if r.pending != nil {
+		r.pending.ids = append(r.pending.ids, ids...)
+		r.pending.dirty = true
+		return
+	}
+
+	keys := []string{statsKey}
+	for _, id := range ids {
+		keys = append(keys, productKey(id))
+	}
+
+	r.mu.Lock()
+	r.epoch.Add(1)
+	r.mu.Unlock()
+	for _, key := range keys {
+		r.group.Forget(key)
+	}
+	// The write has committed; a cancelled request must not leave stale
+	// entries behind.
+	if err := r.store.Delete(context.WithoutCancel(ctx), keys...); err != nil {
+		r.logger.Warn("cache_invalidate_failed", slog.Any("keys", keys), slog.String("error", err.Error()))
+	}
+}
+
+// withVariants adds the variants of top-level product id to ids, since a
+// change to a parent's price is passed on to them.
+func (r *cachedProductRepo) withVariants(ctx context.Context, id int64, ids []int64) []int64 {
+	ids = append(ids, id)
+	variants, err := r.ProductRepository.ListVariants(ctx, id)
+	if err != nil {
+		r.logger.Warn("cache_list_variants_failed", slog.Int64("product_id", id), slog.String("error", err.Error()))
+		return ids
+	}
+	for _, v := range variants {
+		ids = append(ids, v.ID)
+	}
+	return ids
+}
+
+func (r *cachedProductRepo) Create(ctx context.Context, p *model.Product) error {
+	if err := r.ProductRepository.Create(ctx, p); err != nil {
+		return err
+	}
+	r.invalidate(ctx, p.ID)
+	return nil
+}
+
+func (r *cachedProductRepo) Update(ctx context.Context, p *model.Product) error {
+	if err := r.ProductRepository.Update(ctx, p); err != nil {
+		return err
+	}
+	if p.ParentID == nil {
+		r.invalidate(ctx, r.withVariants(ctx, p.ID, nil)...)
+	} else {
+		r.invalidate(ctx, p.ID)
+	}
+	return nil
+}
+
+func (r *cachedProductRepo) Delete(ctx context.Context, id int64) error {
+	if err := r.ProductRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.invalidate(ctx, id)
+	return nil
+}
+
+func (r *cachedProductRepo) SetStatus(ctx context.Context, id int64, to model.ProductStatus) error {
+	if err := r.ProductRepository.SetStatus(ctx, id, to); err != nil {
+		return err
+	}
+	r.invalidate(ctx, id)
+	return nil
+}
+
+func (r *cachedProductRepo) SetBundle(ctx context.Context, b *model.Bundle) error {
+	if err := r.ProductRepository.SetBundle(ctx, b); err != nil {
+		return err
+	}
+	r.invalidate(ctx, b.ProductID)
+	return nil
+}
+
+func (r *cachedProductRepo) RecordMovement(ctx context.Context, m *model.StockMovement) error {
+	if err := r.ProductRepository.RecordMovement(ctx, m); err != nil {
+		return err
+	}
+	r.invalidate(ctx, m.ProductID)
+	return nil
+}
+
+func (r *cachedProductRepo) AdjustStock(ctx context.Context, productID int64, a model.StockAdjustment) (*model.StockMovement, error) {
+	m, err := r.ProductRepository.AdjustStock(ctx, productID, a)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx, productID)
+	return m, nil
+}
+
+func (r *cachedProductRepo) Batch(ctx context.Context, ops []model.BatchOperation, atomic bool) ([]error, error) {
+	errs, err := r.ProductRepository.Batch(ctx, ops, atomic)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for i, op := range ops {
+		if errs[i] != nil {
+			continue
+		}
+		switch {
+		case op.Action == model.BatchCreate:
+			ids = append(ids, op.Product.ID)
+		case op.Action == model.BatchUpdate && op.Product.ParentID == nil:
+			ids = r.withVariants(ctx, op.ID, ids)
+		default:
+			ids = append(ids, op.ID)
+		}
+	}
+	if len(ids) > 0 {
+		r.invalidate(ctx, ids...)
+	}
+	return errs, nil
+}
+
+// WithTx drops what the unit of work wrote once it has finished. That is done
+// even when it failed, as a failed commit may still have taken effect.
+func (r *cachedProductRepo) WithTx(ctx context.Context, fn func(tx ProductRepository) error) error {
+	pending := r.pending
+	if pending == nil {
+		pending = &pendingInvalidation{}
+	}
+	err := r.ProductRepository.WithTx(ctx, func(tx ProductRepository) error {
+		return fn(&cachedProductRepo{ProductRepository: tx, store: r.store, ttl: r.ttl, logger: r.logger, pending: pending})
+	})
+	if r.pending == nil && pending.dirty {
+		r.invalidate(ctx, pending.ids...)
+	}
+	return err
+}
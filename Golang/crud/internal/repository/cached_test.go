@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"golang-sql/internal/cache"
+	"golang-sql/internal/model"
+)
+
+// stockRepo returns a product whose stock is whatever it was last given.
+type stockRepo struct {
+	ProductRepository
+	stock int
+}
+
+func (r *stockRepo) GetByID(_ context.Context, id int64) (*model.Product, error) {
+	return &model.Product{ID: id, StockQty: r.stock}, nil
+}
+
+func TestCachedProductRepoReadPrimary(t *testing.T) {
+	ctx := context.Background()
+	repo := &stockRepo{stock: 5}
+	cached := NewCachedProductRepo(repo, cache.NewLRU(16), time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if _, err := cached.GetByID(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	// A sale elsewhere changes the stock without invalidating the entry.
+	repo.stock = 3
+
+	p, err := cached.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.StockQty != 5 {
+		t.Fatalf("got stock %d from the cache, want the cached 5", p.StockQty)
+	}
+	p, err = cached.GetByID(ReadPrimary(ctx), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.StockQty != 3 {
+		t.Errorf("got stock %d reading the primary, want 3", p.StockQty)
+	}
+}
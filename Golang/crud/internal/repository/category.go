@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+
+	"golang-sql/internal/model"
+)
+
+var (
+	ErrCategoryNotFound  = errors.New("category not found")
+	ErrCategoryNameTaken = errors.New("category name already in use")
+	ErrInvalidAttributes = errors.New("invalid attributes")
+)
+
+type CategoryRepository interface {
+	List(ctx context.Context) ([]model.Category, error)
+	GetByID(ctx context.Context, id int64) (*model.Category, error)
+	Create(ctx context.Context, c *model.Category) error
+	Update(ctx context.Context, c *model.Category) error
+	Close() error
+}
+
+type mysqlCategoryRepo struct {
+	db *sql.DB
+}
+
+func NewMySQLCategoryRepo(db *sql.DB) (CategoryRepository, error) {
+	return &mysqlCategoryRepo{db: db}, nil
+}
+
+func (r *mysqlCategoryRepo) Close() error {
+	return nil
+}
+
+func scanCategory(row rowScanner, c *model.Category) error {
+	var schema []byte
+	if err := row.Scan(&c.ID, &c.Name, &schema, &c.CreatedAt); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(schema, &c.Attributes); err != nil {
+		return fmt.Errorf("decoding schema of category %d: %w", c.ID, err)
+	}
+	if c.Attributes == nil {
+		c.Attributes = []model.AttributeDef{}
+	}
+	return nil
+}
+
+func (r *mysqlCategoryRepo) List(ctx context.Context) ([]model.Category, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT category_id, name, attribute_schema, created_at FROM categories ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("listing categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []model.Category{}
+	for rows.Next() {
+		var c model.Category
+		if err := scanCategory(rows, &c); err != nil {
+			return nil, fmt.Errorf("scanning category row: %w", err)
+		}
+		categories = append(categories, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating category rows: %w", err)
+	}
+	return categories, nil
+}
+
+func (r *mysqlCategoryRepo) GetByID(ctx context.Context, id int64) (*model.Category, error) {
+	c, err := getCategory(ctx, r.db, id, false)
+	if errors.Is(err, ErrCategoryNotFound) {
+		return nil, nil
+	}
+	return c, err
+}
+
+func (r *mysqlCategoryRepo) Create(ctx context.Context, c *model.Category) error {
+	schema, err := json.Marshal(c.Attributes)
+	if err != nil {
+		return fmt.Errorf("encoding attribute schema: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO categories (name, attribute_schema) VALUES (?, ?)", c.Name, schema)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return fmt.Errorf("category %q: %w", c.Name, ErrCategoryNameTaken)
+		}
+		return fmt.Errorf("creating category: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("getting last insert id: %w", err)
+	}
+
+	created, err := getCategory(ctx, r.db, id, false)
+	if err != nil {
+		return err
+	}
+	*c = *created
+	return nil
+}
+
+func (r *mysqlCategoryRepo) Update(ctx context.Context, c *model.Category) error {
+	schema, err := json.Marshal(c.Attributes)
+	if err != nil {
+		return fmt.Errorf("encoding attribute schema: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE categories SET name = ?, attribute_schema = ? WHERE category_id = ?", c.Name, schema, c.ID)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return fmt.Errorf("category %q: %w", c.Name, ErrCategoryNameTaken)
+		}
+		return fmt.Errorf("updating category %d: %w", c.ID, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking rows affected: %w", err)
+	}
+	if affected == 0 {
+		if _, err := getCategory(ctx, r.db, c.ID, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getCategory(ctx context.Context, q queryer, id int64, lock bool) (*model.Category, error) {
+	query := "SELECT category_id, name, attribute_schema, created_at FROM categories WHERE category_id = ?"
+	if lock {
+		query += " FOR SHARE"
+	}
+
+	var c model.Category
+	err := scanCategory(q.QueryRowContext(ctx, query, id), &c)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("category %d: %w", id, ErrCategoryNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting category %d: %w", id, err)
+	}
+	return &c, nil
+}
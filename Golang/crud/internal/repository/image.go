@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+
+	"golang-sql/internal/model"
+)
+
+var ErrImageNotFound = errors.New("image not found")
+
+type ImageRepository interface {
+	List(ctx context.Context, productID int64) ([]model.ProductImage, error)
+	Add(ctx context.Context, img *model.ProductImage) error
+	Delete(ctx context.Context, productID, imageID int64) (*model.ProductImage, error)
+	Close() error
+}
+
+const imageColumns = `image_id, product_id, storage_key, thumbnail_key, content_type,
+	size_bytes, width, height, position, created_at`
+
+func scanImage(row rowScanner, img *model.ProductImage) error {
+	if err := row.Scan(
+		&img.ID, &img.ProductID, &img.StorageKey, &img.ThumbnailKey, &img.ContentType,
+		&img.SizeBytes, &img.Width, &img.Height, &img.Position, &img.CreatedAt,
+	); err != nil {
+		return err
+	}
+	img.URL = model.MediaPath + img.StorageKey
+	img.ThumbnailURL = model.MediaPath + img.ThumbnailKey
+	return nil
+}
+
+type mysqlImageRepo struct {
+	db *sql.DB
+}
+
+func NewMySQLImageRepo(db *sql.DB) (ImageRepository, error) {
+	return &mysqlImageRepo{db: db}, nil
+}
+
+func (r *mysqlImageRepo) Close() error {
+	return nil
+}
+
+func (r *mysqlImageRepo) List(ctx context.Context, productID int64) ([]model.ProductImage, error) {
+	var exists int
+	err := r.db.QueryRowContext(ctx, "SELECT 1 FROM products WHERE product_id = ?", productID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("product %d: %w", productID, ErrProductNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("checking product %d: %w", productID, err)
+	}
+
+	products := []model.Product{{ID: productID}}
+	if err := attachImages(ctx, r.db, products); err != nil {
+		return nil, err
+	}
+	if products[0].Images == nil {
+		return []model.ProductImage{}, nil
+	}
+	return products[0].Images, nil
+}
+
+// Add records an uploaded image after the product's existing ones.
+func (r *mysqlImageRepo) Add(ctx context.Context, img *model.ProductImage) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning image add: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO product_images
+		   (product_id, storage_key, thumbnail_key, content_type, size_bytes, width, height, position)
+		 SELECT ?, ?, ?, ?, ?, ?, ?, COALESCE(MAX(position) + 1, 0)
+		 FROM product_images WHERE product_id = ?`,
+		img.ProductID, img.StorageKey, img.ThumbnailKey, img.ContentType,
+		img.SizeBytes, img.Width, img.Height, img.ProductID,
+	)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1452 {
+			return fmt.Errorf("product %d: %w", img.ProductID, ErrProductNotFound)
+		}
+		return fmt.Errorf("adding image to product %d: %w", img.ProductID, err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("getting last insert id: %w", err)
+	}
+	if err := touchProduct(ctx, tx, img.ProductID); err != nil {
+		return err
+	}
+
+	if err := scanImage(tx.QueryRowContext(ctx,
+		`SELECT `+imageColumns+` FROM product_images WHERE image_id = ?`, id,
+	), img); err != nil {
+		return fmt.Errorf("getting image %d: %w", id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing image add: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the image row and returns it, so the caller can remove the
+// stored files.
+func (r *mysqlImageRepo) Delete(ctx context.Context, productID, imageID int64) (*model.ProductImage, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("beginning image delete: %w", err)
+	}
+	defer tx.Rollback()
+
+	var img model.ProductImage
+	err = scanImage(tx.QueryRowContext(ctx,
+		`SELECT `+imageColumns+` FROM product_images
+		 WHERE image_id = ? AND product_id = ? FOR UPDATE`, imageID, productID,
+	), &img)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("image %d: %w", imageID, ErrImageNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting image %d: %w", imageID, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_images WHERE image_id = ?", imageID); err != nil {
+		return nil, fmt.Errorf("deleting image %d: %w", imageID, err)
+	}
+	if err := touchProduct(ctx, tx, productID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing image delete: %w", err)
+	}
+	return &img, nil
+}
+
+// attachImages loads the images of every product in products with one query.
+func attachImages(ctx context.Context, q queryer, products []model.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	index := make(map[int64]int, len(products))
+	args := make([]interface{}, 0, len(products))
+	for i, p := range products {
+		index[p.ID] = i
+		args = append(args, p.ID)
+	}
+
+	rows, err := q.QueryContext(ctx,
+		`SELECT `+imageColumns+` FROM product_images
+		 WHERE product_id IN (?`+strings.Repeat(", ?", len(products)-1)+`)
+		 ORDER BY product_id, position, image_id`, args...)
+	if err != nil {
+		return fmt.Errorf("listing product images: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var img model.ProductImage
+		if err := scanImage(rows, &img); err != nil {
+			return fmt.Errorf("scanning product image row: %w", err)
+		}
+		p := &products[index[img.ProductID]]
+		p.Images = append(p.Images, img)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating product image rows: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"golang-sql/internal/model"
+)
+
+var ErrOrderNotFound = errors.New("order not found")
+
+type OrderRepository interface {
+	List(ctx context.Context, status model.OrderStatus, page, pageSize int) ([]model.Order, error)
+	GetByID(ctx context.Context, id int64) (*model.Order, error)
+	Create(ctx context.Context, o *model.Order) error
+	Transition(ctx context.Context, id int64, to model.OrderStatus, c model.OrderStatusChange) error
+	Close() error
+}
+
+type mysqlOrderRepo struct {
+	db *sql.DB
+}
+
+func NewMySQLOrderRepo(db *sql.DB) (OrderRepository, error) {
+	return &mysqlOrderRepo{db: db}, nil
+}
+
+func (r *mysqlOrderRepo) Close() error {
+	return nil
+}
+
+func (r *mysqlOrderRepo) List(ctx context.Context, status model.OrderStatus, page, pageSize int) ([]model.Order, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query := `SELECT order_id, reference, warehouse_id, status, total, actor, created_at, updated_at
+	          FROM orders`
+	var args []interface{}
+	if status != "" {
+		query += " WHERE status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY order_id DESC LIMIT ? OFFSET ?"
+	args = append(args, pageSize, (page-1)*pageSize)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []model.Order{}
+	for rows.Next() {
+		var o model.Order
+		if err := rows.Scan(&o.ID, &o.Reference, &o.WarehouseID, &o.Status, &o.Total, &o.Actor, &o.CreatedAt, &o.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning order row: %w", err)
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating order rows: %w", err)
+	}
+	return orders, nil
+}
+
+func (r *mysqlOrderRepo) GetByID(ctx context.Context, id int64) (*model.Order, error) {
+	o, err := getOrder(ctx, r.db, id, false)
+	if errors.Is(err, ErrOrderNotFound) {
+		return nil, nil
+	}
+	return o, err
+}
+
+// Create records the order and deducts every line from the order's warehouse
+// in one transaction; if any line is short the whole order is rejected.
+func (r *mysqlOrderRepo) Create(ctx context.Context, o *model.Order) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning order: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := requireWarehouse(ctx, tx, o.WarehouseID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx,
+		"INSERT INTO orders (reference, warehouse_id, status, actor) VALUES (?, ?, 'pending', ?)",
+		o.Reference, o.WarehouseID, o.Actor,
+	)
+	if err != nil {
+		return fmt.Errorf("creating order: %w", err)
+	}
+	if o.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("getting last insert id: %w", err)
+	}
+
+	products, err := lockProducts(ctx, tx, itemProductIDs(o.Items))
+	if err != nil {
+		return err
+	}
+
+	var total float64
+	for i := range o.Items {
+		it := &o.Items[i]
+		p, ok := products[it.ProductID]
+		if !ok {
+			return fmt.Errorf("product %d: %w", it.ProductID, ErrProductNotFound)
+		}
+		if !p.Status.Sellable() {
+			return fmt.Errorf("product %d is %s: %w", it.ProductID, p.Status, ErrProductNotForSale)
+		}
+		it.Name, it.UnitPrice = p.Name, p.Price
+
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO order_items (order_id, product_id, name, quantity, unit_price)
+			 VALUES (?, ?, ?, ?, ?)`,
+			o.ID, it.ProductID, it.Name, it.Quantity, it.UnitPrice,
+		); err != nil {
+			return fmt.Errorf("creating order item: %w", err)
+		}
+
+		m := &model.StockMovement{
+			ProductID:   it.ProductID,
+			WarehouseID: o.WarehouseID,
+			Type:        model.MovementSale,
+			Quantity:    -it.Quantity,
+			Reason:      fmt.Sprintf("order %d", o.ID),
+			Actor:       o.Actor,
+		}
+		if err := recordMovement(ctx, tx, m); err != nil {
+			return err
+		}
+		total += it.UnitPrice * float64(it.Quantity)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE orders SET total = ? WHERE order_id = ?", math.Round(total*100)/100, o.ID,
+	); err != nil {
+		return fmt.Errorf("updating order %d total: %w", o.ID, err)
+	}
+
+	created, err := getOrder(ctx, tx, o.ID, false)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing order: %w", err)
+	}
+	*o = *created
+	return nil
+}
+
+// Transition moves an order along its status machine. Cancelling returns
+// every line to the warehouse it was taken from.
+func (r *mysqlOrderRepo) Transition(ctx context.Context, id int64, to model.OrderStatus, c model.OrderStatusChange) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning order transition: %w", err)
+	}
+	defer tx.Rollback()
+
+	o, err := getOrder(ctx, tx, id, true)
+	if err != nil {
+		return err
+	}
+	if !o.Status.CanTransitionTo(to) {
+		return fmt.Errorf("order %d from %s to %s: %w", id, o.Status, to, ErrInvalidTransition)
+	}
+
+	if to == model.OrderCancelled {
+		reason := c.Reason
+		if reason == "" {
+			reason = fmt.Sprintf("order %d cancelled", id)
+		}
+		if _, err := lockProducts(ctx, tx, itemProductIDs(o.Items)); err != nil {
+			return err
+		}
+		for _, it := range o.Items {
+			m := &model.StockMovement{
+				ProductID:   it.ProductID,
+				WarehouseID: o.WarehouseID,
+				Type:        model.MovementReturn,
+				Quantity:    it.Quantity,
+				Reason:      reason,
+				Actor:       c.Actor,
+			}
+			if err := recordMovement(ctx, tx, m); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE orders SET status = ? WHERE order_id = ?", to, id,
+	); err != nil {
+		return fmt.Errorf("updating order %d: %w", id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing order transition: %w", err)
+	}
+	return nil
+}
+
+func itemProductIDs(items []model.OrderItem) []int64 {
+	ids := make([]int64, len(items))
+	for i, it := range items {
+		ids[i] = it.ProductID
+	}
+	return ids
+}
+
+func getOrder(ctx context.Context, q queryer, id int64, lock bool) (*model.Order, error) {
+	query := `SELECT order_id, reference, warehouse_id, status, total, actor, created_at, updated_at
+	          FROM orders WHERE order_id = ?`
+	if lock {
+		query += " FOR UPDATE"
+	}
+
+	var o model.Order
+	err := q.QueryRowContext(ctx, query, id).Scan(
+		&o.ID, &o.Reference, &o.WarehouseID, &o.Status, &o.Total, &o.Actor, &o.CreatedAt, &o.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("order %d: %w", id, ErrOrderNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting order %d: %w", id, err)
+	}
+
+	rows, err := q.QueryContext(ctx,
+		`SELECT item_id, product_id, name, quantity, unit_price
+		 FROM order_items WHERE order_id = ? ORDER BY item_id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("listing items of order %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	o.Items = []model.OrderItem{}
+	for rows.Next() {
+		var it model.OrderItem
+		if err := rows.Scan(&it.ID, &it.ProductID, &it.Name, &it.Quantity, &it.UnitPrice); err != nil {
+			return nil, fmt.Errorf("scanning order item row: %w", err)
+		}
+		it.LineTotal = math.Round(it.UnitPrice*float64(it.Quantity)*100) / 100
+		o.Items = append(o.Items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating order item rows: %w", err)
+	}
+	return &o, nil
+}
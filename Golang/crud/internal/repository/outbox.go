@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang-sql/internal/model"
+)
+
+// OutboxRepository reads the product change events that writes append to the
+// outbox in their own transactions.
+type OutboxRepository interface {
+	Relay(ctx context.Context, limit int, publish func(context.Context, model.Event) error) (int, error)
+	Since(ctx context.Context, after int64, limit int) ([]model.Event, error)
+	Bounds(ctx context.Context) (first, last int64, err error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	Close() error
+}
+
+type mysqlOutboxRepo struct {
+	db *sql.DB
+}
+
+func NewMySQLOutboxRepo(db *sql.DB) (OutboxRepository, error) {
+	return &mysqlOutboxRepo{db: db}, nil
+}
+
+func (r *mysqlOutboxRepo) Close() error {
+	return nil
+}
+
+// Relay locks up to limit unpublished events, oldest first, and hands them to
+// publish in order, marking each one published as it succeeds. It stops at
+// the first failure, recording it against that event, so the event and those
+// after it are retried on the next call in their original order. Delivery is
+// at least once: an event whose publish succeeded is sent again if the mark
+// cannot be committed. Concurrent relays wait on each other's locks rather
+// than publishing out of order. It returns how many events were published.
+func (r *mysqlOutboxRepo) Relay(ctx context.Context, limit int, publish func(context.Context, model.Event) error) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("beginning outbox relay: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT event_id, event_type, product_id, payload, created_at
+		 FROM outbox_events WHERE published_at IS NULL
+		 ORDER BY event_id LIMIT ? FOR UPDATE`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("locking outbox events: %w", err)
+	}
+	events, err := scanEvents(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	var publishErr error
+	for _, e := range events {
+		if publishErr = publish(ctx, e); publishErr != nil {
+			msg := publishErr.Error()
+			if len(msg) > 255 {
+				msg = strings.ToValidUTF8(msg[:255], "")
+			}
+			if _, err := tx.ExecContext(ctx,
+				"UPDATE outbox_events SET attempts = attempts + 1, last_error = ? WHERE event_id = ?", msg, e.ID,
+			); err != nil {
+				return 0, fmt.Errorf("recording failure of outbox event %d: %w", e.ID, err)
+			}
+			break
+		}
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE outbox_events SET published_at = CURRENT_TIMESTAMP(6), attempts = attempts + 1 WHERE event_id = ?", e.ID,
+		); err != nil {
+			return 0, fmt.Errorf("marking outbox event %d published: %w", e.ID, err)
+		}
+		published++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing outbox relay: %w", err)
+	}
+	if publishErr != nil {
+		return published, fmt.Errorf("publishing outbox event %d: %w", events[published].ID, publishErr)
+	}
+	return published, nil
+}
+
+// Since returns up to limit events recorded after the event with ID after,
+// oldest first, whether or not they have been relayed yet.
+func (r *mysqlOutboxRepo) Since(ctx context.Context, after int64, limit int) ([]model.Event, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT event_id, event_type, product_id, payload, created_at
+		 FROM outbox_events WHERE event_id > ?
+		 ORDER BY event_id LIMIT ?`, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing outbox events after %d: %w", after, err)
+	}
+	return scanEvents(rows)
+}
+
+// Bounds returns the IDs of the oldest and newest events still in the outbox,
+// or zeros when it is empty.
+func (r *mysqlOutboxRepo) Bounds(ctx context.Context) (first, last int64, err error) {
+	if err := r.db.QueryRowContext(ctx,
+		"SELECT COALESCE(MIN(event_id), 0), COALESCE(MAX(event_id), 0) FROM outbox_events",
+	).Scan(&first, &last); err != nil {
+		return 0, 0, fmt.Errorf("reading outbox bounds: %w", err)
+	}
+	return first, last, nil
+}
+
+// scanEvents reads and closes rows of event_id, event_type, product_id,
+// payload and created_at.
+func scanEvents(rows *sql.Rows) ([]model.Event, error) {
+	defer rows.Close()
+
+	var events []model.Event
+	for rows.Next() {
+		var (
+			e       model.Event
+			payload []byte
+		)
+		if err := rows.Scan(&e.ID, &e.Type, &e.ProductID, &payload, &e.OccurredAt); err != nil {
+			return nil, fmt.Errorf("scanning outbox event row: %w", err)
+		}
+		if len(payload) > 0 {
+			if err := json.Unmarshal(payload, &e.Product); err != nil {
+				return nil, fmt.Errorf("decoding outbox event %d: %w", e.ID, err)
+			}
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating outbox event rows: %w", err)
+	}
+	return events, nil
+}
+
+// Purge deletes the events recorded before before, published or not.
+func (r *mysqlOutboxRepo) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM outbox_events WHERE created_at < ?", before)
+	if err != nil {
+		return 0, fmt.Errorf("purging outbox events: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("checking rows affected: %w", err)
+	}
+	return n, nil
+}
+
+// recordEvent appends a product change event to the outbox in tx, capturing
+// the product as tx now sees it unless it was deleted.
+func recordEvent(ctx context.Context, tx *sql.Tx, typ model.EventType, productID int64) error {
+	var payload []byte
+	if typ != model.EventProductDeleted {
+		p, err := getProduct(ctx, tx, productID)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			return fmt.Errorf("product %d: %w", productID, ErrProductNotFound)
+		}
+		if payload, err = json.Marshal(p); err != nil {
+			return fmt.Errorf("encoding event for product %d: %w", productID, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO outbox_events (event_type, product_id, payload) VALUES (?, ?, ?)",
+		typ, productID, payload,
+	); err != nil {
+		return fmt.Errorf("recording %s event for product %d: %w", typ, productID, err)
+	}
+	return nil
+}
+
+// recordStockChange records a product.updated event for a change in stock on
+// hand from before to after, followed by a stock.low event if it crossed the
+// reorder point.
+func recordStockChange(ctx context.Context, tx *sql.Tx, productID int64, before, after int) error {
+	if err := recordEvent(ctx, tx, model.EventProductUpdated, productID); err != nil {
+		return err
+	}
+	return recordLowStock(ctx, tx, productID, before, after)
+}
+
+// recordLowStock records a stock.low event if a change in stock on hand from
+// before to after crossed the product's reorder point on the way down.
+func recordLowStock(ctx context.Context, tx *sql.Tx, productID int64, before, after int) error {
+	if after >= before {
+		return nil
+	}
+	var point int
+	if err := tx.QueryRowContext(ctx,
+		"SELECT reorder_point FROM products WHERE product_id = ?", productID,
+	).Scan(&point); err != nil {
+		return fmt.Errorf("reading reorder point of product %d: %w", productID, err)
+	}
+	if point <= 0 || before <= point || after > point {
+		return nil
+	}
+	return recordEvent(ctx, tx, model.EventStockLow, productID)
+}
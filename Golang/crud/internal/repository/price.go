@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"golang-sql/internal/model"
+)
+
+var ErrScheduledPriceNotFound = errors.New("scheduled price not found")
+
+type PriceRepository interface {
+	Overview(ctx context.Context, productID int64) (*model.PriceOverview, error)
+	Schedule(ctx context.Context, sp *model.ScheduledPrice) error
+	CancelScheduled(ctx context.Context, productID, scheduleID int64) error
+	ApplyDue(ctx context.Context) (int, error)
+	Close() error
+}
+
+type mysqlPriceRepo struct {
+	db *sql.DB
+}
+
+func NewMySQLPriceRepo(db *sql.DB) (PriceRepository, error) {
+	return &mysqlPriceRepo{db: db}, nil
+}
+
+func (r *mysqlPriceRepo) Close() error {
+	return nil
+}
+
+func (r *mysqlPriceRepo) Overview(ctx context.Context, productID int64) (*model.PriceOverview, error) {
+	var exists int
+	err := r.db.QueryRowContext(ctx, "SELECT 1 FROM products WHERE product_id = ?", productID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("product %d: %w", productID, ErrProductNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("checking product %d: %w", productID, err)
+	}
+
+	ov := &model.PriceOverview{History: []model.PriceChange{}, Scheduled: []model.ScheduledPrice{}}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT price_change_id, product_id, price, source, actor, changed_at
+		 FROM price_history WHERE product_id = ?
+		 ORDER BY changed_at DESC, price_change_id DESC LIMIT 100`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("listing price history for product %d: %w", productID, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var pc model.PriceChange
+		if err := rows.Scan(&pc.ID, &pc.ProductID, &pc.Price, &pc.Source, &pc.Actor, &pc.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scanning price history row: %w", err)
+		}
+		ov.History = append(ov.History, pc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating price history rows: %w", err)
+	}
+
+	schedRows, err := r.db.QueryContext(ctx,
+		`SELECT schedule_id, product_id, price, effective_at, status, actor, created_at, applied_at
+		 FROM scheduled_prices WHERE product_id = ? AND status = 'pending'
+		 ORDER BY effective_at`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("listing scheduled prices for product %d: %w", productID, err)
+	}
+	defer schedRows.Close()
+	for schedRows.Next() {
+		var (
+			sp        model.ScheduledPrice
+			appliedAt sql.NullTime
+		)
+		if err := schedRows.Scan(&sp.ID, &sp.ProductID, &sp.Price, &sp.EffectiveAt, &sp.Status, &sp.Actor, &sp.CreatedAt, &appliedAt); err != nil {
+			return nil, fmt.Errorf("scanning scheduled price row: %w", err)
+		}
+		if appliedAt.Valid {
+			sp.AppliedAt = &appliedAt.Time
+		}
+		ov.Scheduled = append(ov.Scheduled, sp)
+	}
+	if err := schedRows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating scheduled price rows: %w", err)
+	}
+	return ov, nil
+}
+
+func (r *mysqlPriceRepo) Schedule(ctx context.Context, sp *model.ScheduledPrice) error {
+	var exists int
+	err := r.db.QueryRowContext(ctx, "SELECT 1 FROM products WHERE product_id = ?", sp.ProductID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("product %d: %w", sp.ProductID, ErrProductNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("checking product %d: %w", sp.ProductID, err)
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO scheduled_prices (product_id, price, effective_at, status, actor)
+		 VALUES (?, ?, ?, 'pending', ?)`,
+		sp.ProductID, sp.Price, sp.EffectiveAt, sp.Actor,
+	)
+	if err != nil {
+		return fmt.Errorf("scheduling price for product %d: %w", sp.ProductID, err)
+	}
+	if sp.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("getting last insert id: %w", err)
+	}
+	sp.Status = model.ScheduledPricePending
+	if err := r.db.QueryRowContext(ctx,
+		"SELECT created_at FROM scheduled_prices WHERE schedule_id = ?", sp.ID,
+	).Scan(&sp.CreatedAt); err != nil {
+		return fmt.Errorf("reading scheduled price %d: %w", sp.ID, err)
+	}
+	return nil
+}
+
+func (r *mysqlPriceRepo) CancelScheduled(ctx context.Context, productID, scheduleID int64) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE scheduled_prices SET status = 'cancelled'
+		 WHERE schedule_id = ? AND product_id = ? AND status = 'pending'`,
+		scheduleID, productID,
+	)
+	if err != nil {
+		return fmt.Errorf("cancelling scheduled price %d: %w", scheduleID, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking rows affected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("scheduled price %d: %w", scheduleID, ErrScheduledPriceNotFound)
+	}
+	return nil
+}
+
+// ApplyDue sets the price of every product whose scheduled change has become
+// effective. Changes for the same product are applied oldest first, so the
+// latest one wins. A scheduled price on a variant becomes its price override;
+// one on a parent is passed on to variants that follow it.
+func (r *mysqlPriceRepo) ApplyDue(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("beginning price apply: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT schedule_id, product_id, price, actor
+		 FROM scheduled_prices
+		 WHERE status = 'pending' AND effective_at <= NOW()
+		 ORDER BY effective_at, schedule_id
+		 LIMIT 500
+		 FOR UPDATE SKIP LOCKED`,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("selecting due prices: %w", err)
+	}
+	var due []model.ScheduledPrice
+	for rows.Next() {
+		var sp model.ScheduledPrice
+		if err := rows.Scan(&sp.ID, &sp.ProductID, &sp.Price, &sp.Actor); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning due price row: %w", err)
+		}
+		due = append(due, sp)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterating due price rows: %w", err)
+	}
+
+	for _, sp := range due {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE products
+			 SET price = ?, price_override = IF(parent_id IS NULL, NULL, ?)
+			 WHERE product_id = ?`, sp.Price, sp.Price, sp.ProductID,
+		); err != nil {
+			return 0, fmt.Errorf("applying price to product %d: %w", sp.ProductID, err)
+		}
+		if err := recordPriceChange(ctx, tx, sp.ProductID, sp.Price, model.PriceSourceSchedule, sp.Actor); err != nil {
+			return 0, err
+		}
+		if err := followParentPrice(ctx, tx, sp.ProductID, sp.Price, model.PriceSourceSchedule, sp.Actor); err != nil {
+			return 0, err
+		}
+		if err := recordEvent(ctx, tx, model.EventProductUpdated, sp.ProductID); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE scheduled_prices SET status = 'applied', applied_at = NOW() WHERE schedule_id = ?", sp.ID,
+		); err != nil {
+			return 0, fmt.Errorf("marking scheduled price %d applied: %w", sp.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing price apply: %w", err)
+	}
+	return len(due), nil
+}
+
+func recordPriceChange(ctx context.Context, tx *sql.Tx, productID int64, price float64, source model.PriceSource, actor string) error {
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO price_history (product_id, price, source, actor) VALUES (?, ?, ?, ?)",
+		productID, price, source, actor,
+	); err != nil {
+		return fmt.Errorf("recording price change for product %d: %w", productID, err)
+	}
+	return nil
+}
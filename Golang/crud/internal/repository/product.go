@@ -3,48 +3,189 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
 
 	"golang-sql/internal/model"
 )
 
+var (
+	ErrProductNotFound   = errors.New("product not found")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+	ErrProductInUse      = errors.New("product is referenced by other records")
+	ErrProductNotForSale = errors.New("product is not for sale")
+)
+
 type ProductRepository interface {
-	List(ctx context.Context, search string, page, pageSize int) (*model.PaginatedResponse, error)
+	List(ctx context.Context, f model.ProductFilter, page, pageSize int) (*model.PaginatedResponse, error)
+	Export(ctx context.Context, f model.ProductFilter, fn func(*model.Product) error) error
 	GetByID(ctx context.Context, id int64) (*model.Product, error)
+	GetBySKU(ctx context.Context, sku string) (*model.Product, error)
+	ListVariants(ctx context.Context, parentID int64) ([]model.Product, error)
+	GetBundle(ctx context.Context, id int64) (*model.Bundle, error)
+	SetBundle(ctx context.Context, b *model.Bundle) error
 	Create(ctx context.Context, p *model.Product) error
 	Update(ctx context.Context, p *model.Product) error
 	Delete(ctx context.Context, id int64) error
+	SetStatus(ctx context.Context, id int64, to model.ProductStatus) error
 	Stats(ctx context.Context) (*model.Stats, error)
+	ReorderList(ctx context.Context) ([]model.ReorderSuggestion, error)
+	RecordMovement(ctx context.Context, m *model.StockMovement) error
+	ListMovements(ctx context.Context, productID int64, limit int) ([]model.StockMovement, error)
+	AdjustStock(ctx context.Context, productID int64, a model.StockAdjustment) (*model.StockMovement, error)
+	Batch(ctx context.Context, ops []model.BatchOperation, atomic bool) ([]error, error)
+	WithTx(ctx context.Context, fn func(tx ProductRepository) error) error
 	Close() error
 }
 
+const productColumns = `product_id, parent_id, sku, name, description, options, category_id, price, price_override,
+	stock_quantity, reserved_quantity, reorder_point, reorder_quantity, status, is_bundle, created_at,
+	IF(is_bundle, GREATEST(updated_at, COALESCE((` + bundleUpdatedAt + `), updated_at)), updated_at) AS updated_at,
+	IF(is_bundle, (` + bundleAvailability + `), NULL) AS bundle_available`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanProduct(row rowScanner, p *model.Product) error {
+	var (
+		parentID sql.NullInt64
+		sku      sql.NullString
+		options  []byte
+		category sql.NullInt64
+		override sql.NullFloat64
+		kits     sql.NullInt64
+	)
+	if err := row.Scan(
+		&p.ID, &parentID, &sku, &p.Name, &p.Description, &options, &category, &p.Price, &override,
+		&p.StockQty, &p.ReservedQty, &p.ReorderPoint, &p.ReorderQty, &p.Status, &p.IsBundle, &p.CreatedAt,
+		&p.UpdatedAt, &kits,
+	); err != nil {
+		return err
+	}
+	if p.IsBundle {
+		p.StockQty = int(kits.Int64)
+		p.ReservedQty = 0
+	}
+	if parentID.Valid {
+		p.ParentID = &parentID.Int64
+	}
+	if category.Valid {
+		p.CategoryID = &category.Int64
+	}
+	if override.Valid {
+		p.PriceOverride = &override.Float64
+	}
+	p.SKU = sku.String
+	if len(options) > 0 {
+		if err := json.Unmarshal(options, &p.Options); err != nil {
+			return fmt.Errorf("decoding options of product %d: %w", p.ID, err)
+		}
+	}
+	p.AvailableQty = p.StockQty - p.ReservedQty
+	return nil
+}
+
+// nullableColumns returns the SKU and options of p in the form stored in the
+// database, where an empty SKU or no options is NULL.
+func nullableColumns(p *model.Product) (sku, options any, err error) {
+	if p.SKU != "" {
+		sku = p.SKU
+	}
+	if len(p.Options) > 0 {
+		b, err := json.Marshal(p.Options)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encoding options: %w", err)
+		}
+		options = b
+	}
+	return sku, options, nil
+}
+
+// touchProduct marks the product as modified after a change to rows that hang
+// off it, so that cached copies of it are revalidated.
+func touchProduct(ctx context.Context, tx *sql.Tx, id int64) error {
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE products SET updated_at = CURRENT_TIMESTAMP(6) WHERE product_id = ?", id,
+	); err != nil {
+		return fmt.Errorf("touching product %d: %w", id, err)
+	}
+	return nil
+}
+
+// The queries behind List, GetByID and Stats go through reads, which may send
+// them to a replica, so they are not prepared on the primary.
+const (
+	getProductQuery = `SELECT ` + productColumns + `
+	                   FROM products WHERE product_id = ?`
+	statsQuery = `SELECT
+	                COUNT(*) AS total_products,
+	                COALESCE(SUM(stock_quantity), 0) AS total_stock,
+	                COALESCE(SUM(price * stock_quantity), 0) AS total_value,
+	                COALESCE(SUM(CASE WHEN stock_quantity > 0 AND stock_quantity <= reorder_point THEN 1 ELSE 0 END), 0) AS low_stock
+	              FROM products WHERE status <> 'archived'`
+	warehouseStatsQuery = `SELECT
+	                         w.warehouse_id, w.code, w.name,
+	                         COUNT(p.product_id) AS total_products,
+	                         COALESCE(SUM(ws.quantity), 0) AS total_stock,
+	                         COALESCE(SUM(p.price * ws.quantity), 0) AS total_value
+	                       FROM warehouses w
+	                       LEFT JOIN (warehouse_stock ws
+	                         JOIN products p ON p.product_id = ws.product_id AND p.status <> 'archived')
+	                         ON ws.warehouse_id = w.warehouse_id AND ws.quantity > 0
+	                       GROUP BY w.warehouse_id, w.code, w.name
+	                       ORDER BY w.warehouse_id`
+)
+
 type mysqlProductRepo struct {
-	db          *sql.DB
-	stmtGetByID *sql.Stmt
-	stmtCreate  *sql.Stmt
-	stmtUpdate  *sql.Stmt
-	stmtDelete  *sql.Stmt
-	stmtStats   *sql.Stmt
+	db           *sql.DB
+	reads        *ReadRouter
+	tx           *sql.Tx // set inside WithTx
+	savepoints   *int
+	stmtGetBySKU *sql.Stmt
+	stmtCreate   *sql.Stmt
+	stmtUpdate   *sql.Stmt
+	stmtDelete   *sql.Stmt
+
+	stmtListMovements *sql.Stmt
+	stmtAdjustStock   *sql.Stmt
+
+	stmtReorder *sql.Stmt
 }
 
-func NewMySQLProductRepo(db *sql.DB) (ProductRepository, error) {
+func NewMySQLProductRepo(db *sql.DB, reads *ReadRouter) (ProductRepository, error) {
 	stmts := make(map[string]*sql.Stmt)
 	queries := map[string]string{
-		"getByID": `SELECT product_id, name, description, price, stock_quantity, created_at
-		            FROM products WHERE product_id = ?`,
-		"create": `INSERT INTO products (name, description, price, stock_quantity)
-		           VALUES (?, ?, ?, ?)`,
+		"getBySKU": `SELECT ` + productColumns + `
+		             FROM products WHERE sku = ?`,
+		"create": `INSERT INTO products (parent_id, sku, name, description, options, category_id, price, price_override,
+		             stock_quantity, reorder_point, reorder_quantity, status)
+		           VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		"update": `UPDATE products
-		           SET name = ?, description = ?, price = ?, stock_quantity = ?
+		           SET sku = ?, name = ?, description = ?, options = ?, category_id = ?, price = ?, price_override = ?,
+		               reorder_point = ?, reorder_quantity = ?, status = ?,
+		               updated_at = CURRENT_TIMESTAMP(6)
 		           WHERE product_id = ?`,
 		"delete": `DELETE FROM products WHERE product_id = ?`,
-		"stats": `SELECT
-		            COUNT(*) AS total_products,
-		            COALESCE(SUM(stock_quantity), 0) AS total_stock,
-		            COALESCE(SUM(price * stock_quantity), 0) AS total_value,
-		            COALESCE(SUM(CASE WHEN stock_quantity > 0 AND stock_quantity <= 10 THEN 1 ELSE 0 END), 0) AS low_stock
-		          FROM products`,
+		"reorder": `SELECT product_id, name, stock_quantity, reserved_quantity, reorder_point, reorder_quantity,
+		              GREATEST(reorder_quantity, reorder_point - stock_quantity + 1) AS suggested_quantity
+		            FROM products
+		            WHERE status IN ('draft', 'active') AND is_bundle = 0
+		              AND reorder_point > 0 AND stock_quantity <= reorder_point
+		            ORDER BY stock_quantity - reorder_point, name`,
+		"listMovements": `SELECT movement_id, product_id, warehouse_id, movement_type, quantity, stock_after, reason, actor, created_at
+		                  FROM stock_movements WHERE product_id = ?
+		                  ORDER BY movement_id DESC LIMIT ?`,
+		"adjustStock": `UPDATE products
+		                SET stock_quantity = stock_quantity + ?
+		                WHERE product_id = ? AND is_bundle = 0
+		                  AND (? > 0 OR stock_quantity - reserved_quantity + ? >= 0)`,
 	}
 
 	for name, q := range queries {
@@ -59,17 +200,25 @@ func NewMySQLProductRepo(db *sql.DB) (ProductRepository, error) {
 	}
 
 	return &mysqlProductRepo{
-		db:          db,
-		stmtGetByID: stmts["getByID"],
-		stmtCreate:  stmts["create"],
-		stmtUpdate:  stmts["update"],
-		stmtDelete:  stmts["delete"],
-		stmtStats:   stmts["stats"],
+		db:           db,
+		reads:        reads,
+		stmtGetBySKU: stmts["getBySKU"],
+		stmtCreate:   stmts["create"],
+		stmtUpdate:   stmts["update"],
+		stmtDelete:   stmts["delete"],
+
+		stmtListMovements: stmts["listMovements"],
+		stmtAdjustStock:   stmts["adjustStock"],
+
+		stmtReorder: stmts["reorder"],
 	}, nil
 }
 
 func (r *mysqlProductRepo) Close() error {
-	for _, s := range []*sql.Stmt{r.stmtGetByID, r.stmtCreate, r.stmtUpdate, r.stmtDelete, r.stmtStats} {
+	if r.tx != nil {
+		return nil
+	}
+	for _, s := range []*sql.Stmt{r.stmtGetBySKU, r.stmtCreate, r.stmtUpdate, r.stmtDelete, r.stmtListMovements, r.stmtAdjustStock, r.stmtReorder} {
 		if s != nil {
 			s.Close()
 		}
@@ -77,7 +226,20 @@ func (r *mysqlProductRepo) Close() error {
 	return nil
 }
 
-func (r *mysqlProductRepo) List(ctx context.Context, search string, page, pageSize int) (*model.PaginatedResponse, error) {
+// List pages through products matching f. Flat listings include variants as
+// ordinary rows; grouped listings page through top-level products only and
+// attach each one's variants.
+func (r *mysqlProductRepo) List(ctx context.Context, f model.ProductFilter, page, pageSize int) (*model.PaginatedResponse, error) {
+	var resp *model.PaginatedResponse
+	err := r.read(ctx, func(q queryer) error {
+		var err error
+		resp, err = listProducts(ctx, q, f, page, pageSize)
+		return err
+	})
+	return resp, err
+}
+
+func listProducts(ctx context.Context, q queryer, f model.ProductFilter, page, pageSize int) (*model.PaginatedResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -86,32 +248,19 @@ func (r *mysqlProductRepo) List(ctx context.Context, search string, page, pageSi
 	}
 	offset := (page - 1) * pageSize
 
-	var (
-		countQuery string
-		listQuery  string
-		args       []interface{}
-	)
-
-	if search != "" {
-		like := "%" + search + "%"
-		countQuery = "SELECT COUNT(*) FROM products WHERE name LIKE ? OR description LIKE ?"
-		listQuery = `SELECT product_id, name, description, price, stock_quantity, created_at
-		             FROM products WHERE name LIKE ? OR description LIKE ?
-		             ORDER BY created_at DESC LIMIT ? OFFSET ?`
-		args = []interface{}{like, like}
-	} else {
-		countQuery = "SELECT COUNT(*) FROM products"
-		listQuery = `SELECT product_id, name, description, price, stock_quantity, created_at
-		             FROM products ORDER BY created_at DESC LIMIT ? OFFSET ?`
-	}
+	where, args := productWhere(f)
+	countQuery := "SELECT COUNT(*) FROM products" + where
+	listQuery := `SELECT ` + productColumns + `
+	              FROM products` + where + `
+	              ORDER BY created_at DESC LIMIT ? OFFSET ?`
 
 	var total int
-	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+	if err := q.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
 		return nil, fmt.Errorf("counting products: %w", err)
 	}
 
 	listArgs := append(args, pageSize, offset)
-	rows, err := r.db.QueryContext(ctx, listQuery, listArgs...)
+	rows, err := q.QueryContext(ctx, listQuery, listArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("listing products: %w", err)
 	}
@@ -120,7 +269,7 @@ func (r *mysqlProductRepo) List(ctx context.Context, search string, page, pageSi
 	products := make([]model.Product, 0, min(pageSize, total))
 	for rows.Next() {
 		var p model.Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.Price, &p.StockQty, &p.CreatedAt); err != nil {
+		if err := scanProduct(rows, &p); err != nil {
 			return nil, fmt.Errorf("scanning product row: %w", err)
 		}
 		products = append(products, p)
@@ -128,6 +277,14 @@ func (r *mysqlProductRepo) List(ctx context.Context, search string, page, pageSi
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterating product rows: %w", err)
 	}
+	if err := attachDetails(ctx, q, products); err != nil {
+		return nil, err
+	}
+	if f.Grouped {
+		if err := attachVariants(ctx, q, products, f.Status); err != nil {
+			return nil, err
+		}
+	}
 
 	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
 
@@ -140,51 +297,313 @@ func (r *mysqlProductRepo) List(ctx context.Context, search string, page, pageSi
 	}, nil
 }
 
+// productWhere builds the WHERE clause, if any, selecting the products that
+// match f.
+func productWhere(f model.ProductFilter) (string, []interface{}) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+	if f.Search != "" {
+		like := "%" + f.Search + "%"
+		conds = append(conds, "(name LIKE ? OR description LIKE ? OR sku = ?)")
+		args = append(args, like, like, f.Search)
+	}
+	if f.Status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, f.Status)
+	}
+	if f.Grouped {
+		conds = append(conds, "parent_id IS NULL")
+	}
+	if f.CategoryID != 0 {
+		conds = append(conds, "category_id = ?")
+		args = append(args, f.CategoryID)
+	}
+	attrConds, attrArgs := attributeConditions(f.Attributes)
+	conds = append(conds, attrConds...)
+	args = append(args, attrArgs...)
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// Export calls fn for every product matching f, in ID order, as rows arrive
+// from the database, so the catalog can be written out without being held in
+// memory. Images and attributes are not loaded, and grouped filters yield the
+// top-level products without their variants. Export stops at the first error
+// fn returns.
+func (r *mysqlProductRepo) Export(ctx context.Context, f model.ProductFilter, fn func(*model.Product) error) error {
+	where, args := productWhere(f)
+	rows, err := r.conn().QueryContext(ctx, `SELECT `+productColumns+`
+	                                   FROM products`+where+`
+	                                   ORDER BY product_id`, args...)
+	if err != nil {
+		return fmt.Errorf("exporting products: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p model.Product
+		if err := scanProduct(rows, &p); err != nil {
+			return fmt.Errorf("scanning product row: %w", err)
+		}
+		if err := fn(&p); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating product rows: %w", err)
+	}
+	return nil
+}
+
 func (r *mysqlProductRepo) GetByID(ctx context.Context, id int64) (*model.Product, error) {
+	var p *model.Product
+	err := r.read(ctx, func(q queryer) error {
+		var err error
+		p, err = getProduct(ctx, q, id)
+		return err
+	})
+	return p, err
+}
+
+func getProduct(ctx context.Context, q queryer, id int64) (*model.Product, error) {
 	var p model.Product
-	err := r.stmtGetByID.QueryRowContext(ctx, id).Scan(
-		&p.ID, &p.Name, &p.Description, &p.Price, &p.StockQty, &p.CreatedAt,
-	)
+	err := scanProduct(q.QueryRowContext(ctx, getProductQuery, id), &p)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("getting product %d: %w", id, err)
 	}
-	return &p, nil
+	products := []model.Product{p}
+	if err := attachDetails(ctx, q, products); err != nil {
+		return nil, err
+	}
+	return &products[0], nil
+}
+
+func (r *mysqlProductRepo) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	var p model.Product
+	err := scanProduct(r.stmt(ctx, r.stmtGetBySKU).QueryRowContext(ctx, sku), &p)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting product with sku %q: %w", sku, err)
+	}
+	products := []model.Product{p}
+	if err := attachDetails(ctx, r.conn(), products); err != nil {
+		return nil, err
+	}
+	return &products[0], nil
 }
 
+// Create inserts a new product. Products start as active unless created as
+// drafts; they cannot be created directly as discontinued or archived.
 func (r *mysqlProductRepo) Create(ctx context.Context, p *model.Product) error {
-	result, err := r.stmtCreate.ExecContext(ctx, p.Name, p.Description, p.Price, p.StockQty)
+	tx, err := r.begin(ctx)
 	if err != nil {
-		return fmt.Errorf("creating product: %w", err)
+		return fmt.Errorf("beginning product create: %w", err)
 	}
-	id, err := result.LastInsertId()
+	defer tx.Rollback()
+
+	id, err := r.createProduct(ctx, tx.Tx, p)
 	if err != nil {
-		return fmt.Errorf("getting last insert id: %w", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing product create: %w", err)
 	}
 	p.ID = id
 	return nil
 }
 
+func (r *mysqlProductRepo) createProduct(ctx context.Context, tx *sql.Tx, p *model.Product) (int64, error) {
+	if p.Status == "" {
+		p.Status = model.ProductActive
+	}
+	if p.Status != model.ProductDraft && p.Status != model.ProductActive {
+		return 0, fmt.Errorf("new product as %s: %w", p.Status, ErrInvalidTransition)
+	}
+	sku, options, err := nullableColumns(p)
+	if err != nil {
+		return 0, err
+	}
+
+	if p.ParentID != nil {
+		if p.Price, err = variantPrice(ctx, tx, *p.ParentID, p.PriceOverride); err != nil {
+			return 0, err
+		}
+	} else {
+		p.PriceOverride = nil
+	}
+
+	result, err := tx.StmtContext(ctx, r.stmtCreate).ExecContext(ctx,
+		p.ParentID, sku, p.Name, p.Description, options, p.CategoryID, p.Price, p.PriceOverride,
+		p.StockQty, p.ReorderPoint, p.ReorderQty, p.Status,
+	)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return 0, fmt.Errorf("sku %q: %w", p.SKU, ErrSKUTaken)
+		}
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1452 {
+			return 0, fmt.Errorf("category %d: %w", *p.CategoryID, ErrCategoryNotFound)
+		}
+		return 0, fmt.Errorf("creating product: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("getting last insert id: %w", err)
+	}
+
+	if err := saveAttributes(ctx, tx, id, p.CategoryID, p.Attributes); err != nil {
+		return 0, err
+	}
+	if p.StockQty > 0 {
+		if err := applyLocationDelta(ctx, tx, model.DefaultWarehouseID, id, p.StockQty); err != nil {
+			return 0, err
+		}
+		if err := insertMovement(ctx, tx, &model.StockMovement{
+			ProductID:   id,
+			WarehouseID: model.DefaultWarehouseID,
+			Type:        model.MovementReceipt,
+			Quantity:    p.StockQty,
+			StockAfter:  p.StockQty,
+			Reason:      "initial stock",
+			Actor:       systemActor,
+		}); err != nil {
+			return 0, err
+		}
+	}
+	if err := recordPriceChange(ctx, tx, id, p.Price, model.PriceSourceCreate, ""); err != nil {
+		return 0, err
+	}
+	if err := recordEvent(ctx, tx, model.EventProductCreated, id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Update overwrites the product, appending any change in price to the price
+// history. Stock is left alone: it only changes through the stock ledger, so
+// a client writing back a quantity it read earlier cannot undo sales made in
+// between. p.StockQty is set to the stock on hand. An empty status keeps
+// the current one; any other change must be an allowed transition. A product
+// never changes parent; a new parent price is passed on to variants without
+// a price override.
 func (r *mysqlProductRepo) Update(ctx context.Context, p *model.Product) error {
-	result, err := r.stmtUpdate.ExecContext(ctx, p.Name, p.Description, p.Price, p.StockQty, p.ID)
+	tx, err := r.begin(ctx)
 	if err != nil {
+		return fmt.Errorf("beginning product update: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.updateProduct(ctx, tx.Tx, p); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing product update: %w", err)
+	}
+	return nil
+}
+
+func (r *mysqlProductRepo) updateProduct(ctx context.Context, tx *sql.Tx, p *model.Product) error {
+	sku, options, err := nullableColumns(p)
+	if err != nil {
+		return err
+	}
+
+	var (
+		current   int
+		oldPrice  float64
+		oldStatus model.ProductStatus
+		parentID  sql.NullInt64
+	)
+	err = tx.QueryRowContext(ctx,
+		"SELECT stock_quantity, price, status, parent_id FROM products WHERE product_id = ? FOR UPDATE", p.ID,
+	).Scan(&current, &oldPrice, &oldStatus, &parentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("product %d: %w", p.ID, ErrProductNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("locking product %d: %w", p.ID, err)
+	}
+	p.StockQty = current
+	if p.Status == "" {
+		p.Status = oldStatus
+	}
+	if p.Status != oldStatus && !oldStatus.CanTransitionTo(p.Status) {
+		return fmt.Errorf("product %d from %s to %s: %w", p.ID, oldStatus, p.Status, ErrInvalidTransition)
+	}
+	if parentID.Valid {
+		p.ParentID = &parentID.Int64
+		if p.Price, err = variantPrice(ctx, tx, parentID.Int64, p.PriceOverride); err != nil {
+			return err
+		}
+	} else {
+		p.ParentID = nil
+		p.PriceOverride = nil
+	}
+
+	if _, err := tx.StmtContext(ctx, r.stmtUpdate).ExecContext(ctx,
+		sku, p.Name, p.Description, options, p.CategoryID, p.Price, p.PriceOverride,
+		p.ReorderPoint, p.ReorderQty, p.Status, p.ID,
+	); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return fmt.Errorf("sku %q: %w", p.SKU, ErrSKUTaken)
+		}
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1452 {
+			return fmt.Errorf("category %d: %w", *p.CategoryID, ErrCategoryNotFound)
+		}
 		return fmt.Errorf("updating product %d: %w", p.ID, err)
 	}
-	affected, err := result.RowsAffected()
+	if err := saveAttributes(ctx, tx, p.ID, p.CategoryID, p.Attributes); err != nil {
+		return err
+	}
+
+	if math.Round(p.Price*100) != math.Round(oldPrice*100) {
+		if err := recordPriceChange(ctx, tx, p.ID, p.Price, model.PriceSourceUpdate, ""); err != nil {
+			return err
+		}
+		if err := followParentPrice(ctx, tx, p.ID, p.Price, model.PriceSourceUpdate, ""); err != nil {
+			return err
+		}
+	}
+	return recordEvent(ctx, tx, model.EventProductUpdated, p.ID)
+}
+
+func (r *mysqlProductRepo) Delete(ctx context.Context, id int64) error {
+	tx, err := r.begin(ctx)
 	if err != nil {
-		return fmt.Errorf("checking rows affected: %w", err)
+		return fmt.Errorf("beginning product delete: %w", err)
 	}
-	if affected == 0 {
-		return fmt.Errorf("product %d not found", p.ID)
+	defer tx.Rollback()
+
+	if err := r.deleteProduct(ctx, tx.Tx, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing product delete: %w", err)
 	}
 	return nil
 }
 
-func (r *mysqlProductRepo) Delete(ctx context.Context, id int64) error {
-	result, err := r.stmtDelete.ExecContext(ctx, id)
+func (r *mysqlProductRepo) deleteProduct(ctx context.Context, tx *sql.Tx, id int64) error {
+	result, err := tx.StmtContext(ctx, r.stmtDelete).ExecContext(ctx, id)
 	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1451 {
+			return fmt.Errorf("product %d: %w", id, ErrProductInUse)
+		}
 		return fmt.Errorf("deleting product %d: %w", id, err)
 	}
 	affected, err := result.RowsAffected()
@@ -192,18 +611,103 @@ func (r *mysqlProductRepo) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("checking rows affected: %w", err)
 	}
 	if affected == 0 {
-		return fmt.Errorf("product %d not found", id)
+		return fmt.Errorf("product %d: %w", id, ErrProductNotFound)
+	}
+	return recordEvent(ctx, tx, model.EventProductDeleted, id)
+}
+
+func (r *mysqlProductRepo) SetStatus(ctx context.Context, id int64, to model.ProductStatus) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning product status change: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current model.ProductStatus
+	err = tx.QueryRowContext(ctx,
+		"SELECT status FROM products WHERE product_id = ? FOR UPDATE", id,
+	).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("product %d: %w", id, ErrProductNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("locking product %d: %w", id, err)
+	}
+	if !current.CanTransitionTo(to) {
+		return fmt.Errorf("product %d from %s to %s: %w", id, current, to, ErrInvalidTransition)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE products SET status = ? WHERE product_id = ?", to, id,
+	); err != nil {
+		return fmt.Errorf("updating product %d status: %w", id, err)
+	}
+	if err := recordEvent(ctx, tx.Tx, model.EventProductUpdated, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing product status change: %w", err)
 	}
 	return nil
 }
 
 func (r *mysqlProductRepo) Stats(ctx context.Context) (*model.Stats, error) {
+	var s *model.Stats
+	err := r.read(ctx, func(q queryer) error {
+		var err error
+		s, err = productStats(ctx, q)
+		return err
+	})
+	return s, err
+}
+
+func productStats(ctx context.Context, q queryer) (*model.Stats, error) {
 	var s model.Stats
-	err := r.stmtStats.QueryRowContext(ctx).Scan(
+	err := q.QueryRowContext(ctx, statsQuery).Scan(
 		&s.TotalProducts, &s.TotalStock, &s.TotalValue, &s.LowStockCount,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("computing stats: %w", err)
 	}
+
+	rows, err := q.QueryContext(ctx, warehouseStatsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("computing warehouse stats: %w", err)
+	}
+	defer rows.Close()
+
+	s.Warehouses = []model.WarehouseStats{}
+	for rows.Next() {
+		var ws model.WarehouseStats
+		if err := rows.Scan(&ws.WarehouseID, &ws.Code, &ws.Name, &ws.TotalProducts, &ws.TotalStock, &ws.TotalValue); err != nil {
+			return nil, fmt.Errorf("scanning warehouse stats row: %w", err)
+		}
+		s.Warehouses = append(s.Warehouses, ws)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating warehouse stats rows: %w", err)
+	}
 	return &s, nil
 }
+
+func (r *mysqlProductRepo) ReorderList(ctx context.Context) ([]model.ReorderSuggestion, error) {
+	rows, err := r.stmt(ctx, r.stmtReorder).QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing reorder suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := []model.ReorderSuggestion{}
+	for rows.Next() {
+		var s model.ReorderSuggestion
+		if err := rows.Scan(&s.ProductID, &s.Name, &s.StockQty, &s.ReservedQty, &s.ReorderPoint, &s.ReorderQty, &s.SuggestedQty); err != nil {
+			return nil, fmt.Errorf("scanning reorder row: %w", err)
+		}
+		suggestions = append(suggestions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating reorder rows: %w", err)
+	}
+	return suggestions, nil
+}
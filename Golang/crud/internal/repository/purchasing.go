@@ -0,0 +1,386 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+
+	"golang-sql/internal/model"
+)
+
+var (
+	ErrSupplierNotFound      = errors.New("supplier not found")
+	ErrPurchaseOrderNotFound = errors.New("purchase order not found")
+	ErrInvalidTransition     = errors.New("invalid status transition")
+	ErrOverReceipt           = errors.New("received quantity exceeds quantity ordered")
+	ErrItemNotOnOrder        = errors.New("product is not on the purchase order")
+)
+
+type PurchasingRepository interface {
+	ListSuppliers(ctx context.Context) ([]model.Supplier, error)
+	GetSupplier(ctx context.Context, id int64) (*model.Supplier, error)
+	CreateSupplier(ctx context.Context, s *model.Supplier) error
+	UpdateSupplier(ctx context.Context, s *model.Supplier) error
+
+	ListPurchaseOrders(ctx context.Context, status model.PurchaseOrderStatus) ([]model.PurchaseOrder, error)
+	GetPurchaseOrder(ctx context.Context, id int64) (*model.PurchaseOrder, error)
+	CreatePurchaseOrder(ctx context.Context, po *model.PurchaseOrder) error
+	TransitionPurchaseOrder(ctx context.Context, id int64, to model.PurchaseOrderStatus) error
+	ReceivePurchaseOrder(ctx context.Context, id int64, rcpt model.PurchaseOrderReceipt) error
+	Close() error
+}
+
+type mysqlPurchasingRepo struct {
+	db *sql.DB
+}
+
+func NewMySQLPurchasingRepo(db *sql.DB) (PurchasingRepository, error) {
+	return &mysqlPurchasingRepo{db: db}, nil
+}
+
+func (r *mysqlPurchasingRepo) Close() error {
+	return nil
+}
+
+func (r *mysqlPurchasingRepo) ListSuppliers(ctx context.Context) ([]model.Supplier, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT supplier_id, name, email, phone, created_at FROM suppliers ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("listing suppliers: %w", err)
+	}
+	defer rows.Close()
+
+	suppliers := []model.Supplier{}
+	for rows.Next() {
+		var s model.Supplier
+		if err := rows.Scan(&s.ID, &s.Name, &s.Email, &s.Phone, &s.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning supplier row: %w", err)
+		}
+		suppliers = append(suppliers, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating supplier rows: %w", err)
+	}
+	return suppliers, nil
+}
+
+func (r *mysqlPurchasingRepo) GetSupplier(ctx context.Context, id int64) (*model.Supplier, error) {
+	var s model.Supplier
+	err := r.db.QueryRowContext(ctx,
+		"SELECT supplier_id, name, email, phone, created_at FROM suppliers WHERE supplier_id = ?", id,
+	).Scan(&s.ID, &s.Name, &s.Email, &s.Phone, &s.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting supplier %d: %w", id, err)
+	}
+	return &s, nil
+}
+
+func (r *mysqlPurchasingRepo) CreateSupplier(ctx context.Context, s *model.Supplier) error {
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO suppliers (name, email, phone) VALUES (?, ?, ?)", s.Name, s.Email, s.Phone)
+	if err != nil {
+		return fmt.Errorf("creating supplier: %w", err)
+	}
+	if s.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("getting last insert id: %w", err)
+	}
+	if err := r.db.QueryRowContext(ctx,
+		"SELECT created_at FROM suppliers WHERE supplier_id = ?", s.ID,
+	).Scan(&s.CreatedAt); err != nil {
+		return fmt.Errorf("reading supplier %d: %w", s.ID, err)
+	}
+	return nil
+}
+
+func (r *mysqlPurchasingRepo) UpdateSupplier(ctx context.Context, s *model.Supplier) error {
+	var exists int
+	err := r.db.QueryRowContext(ctx, "SELECT 1 FROM suppliers WHERE supplier_id = ?", s.ID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("supplier %d: %w", s.ID, ErrSupplierNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("checking supplier %d: %w", s.ID, err)
+	}
+
+	if _, err := r.db.ExecContext(ctx,
+		"UPDATE suppliers SET name = ?, email = ?, phone = ? WHERE supplier_id = ?",
+		s.Name, s.Email, s.Phone, s.ID,
+	); err != nil {
+		return fmt.Errorf("updating supplier %d: %w", s.ID, err)
+	}
+	return nil
+}
+
+func (r *mysqlPurchasingRepo) ListPurchaseOrders(ctx context.Context, status model.PurchaseOrderStatus) ([]model.PurchaseOrder, error) {
+	query := `SELECT po_id, supplier_id, warehouse_id, status, notes, created_at, updated_at
+	          FROM purchase_orders`
+	var args []interface{}
+	if status != "" {
+		query += " WHERE status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY po_id DESC LIMIT 100"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing purchase orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []model.PurchaseOrder{}
+	index := make(map[int64]int)
+	for rows.Next() {
+		var po model.PurchaseOrder
+		if err := rows.Scan(&po.ID, &po.SupplierID, &po.WarehouseID, &po.Status, &po.Notes, &po.CreatedAt, &po.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning purchase order row: %w", err)
+		}
+		po.Items = []model.PurchaseOrderItem{}
+		index[po.ID] = len(orders)
+		orders = append(orders, po)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating purchase order rows: %w", err)
+	}
+	if len(orders) == 0 {
+		return orders, nil
+	}
+
+	ids := make([]interface{}, 0, len(orders))
+	for _, po := range orders {
+		ids = append(ids, po.ID)
+	}
+	itemRows, err := r.db.QueryContext(ctx,
+		`SELECT po_id, item_id, product_id, quantity_ordered, quantity_received, unit_cost
+		 FROM purchase_order_items
+		 WHERE po_id IN (?`+strings.Repeat(", ?", len(ids)-1)+`)
+		 ORDER BY item_id`, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("listing purchase order items: %w", err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var (
+			poID int64
+			it   model.PurchaseOrderItem
+		)
+		if err := itemRows.Scan(&poID, &it.ID, &it.ProductID, &it.QuantityOrdered, &it.QuantityReceived, &it.UnitCost); err != nil {
+			return nil, fmt.Errorf("scanning purchase order item row: %w", err)
+		}
+		po := &orders[index[poID]]
+		po.Items = append(po.Items, it)
+	}
+	if err := itemRows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating purchase order item rows: %w", err)
+	}
+	return orders, nil
+}
+
+func (r *mysqlPurchasingRepo) GetPurchaseOrder(ctx context.Context, id int64) (*model.PurchaseOrder, error) {
+	po, err := getPurchaseOrder(ctx, r.db, id, false)
+	if errors.Is(err, ErrPurchaseOrderNotFound) {
+		return nil, nil
+	}
+	return po, err
+}
+
+func (r *mysqlPurchasingRepo) CreatePurchaseOrder(ctx context.Context, po *model.PurchaseOrder) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning purchase order: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRowContext(ctx, "SELECT 1 FROM suppliers WHERE supplier_id = ?", po.SupplierID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("supplier %d: %w", po.SupplierID, ErrSupplierNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("checking supplier %d: %w", po.SupplierID, err)
+	}
+	if err := requireWarehouse(ctx, tx, po.WarehouseID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx,
+		"INSERT INTO purchase_orders (supplier_id, warehouse_id, status, notes) VALUES (?, ?, 'draft', ?)",
+		po.SupplierID, po.WarehouseID, po.Notes,
+	)
+	if err != nil {
+		return fmt.Errorf("creating purchase order: %w", err)
+	}
+	if po.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("getting last insert id: %w", err)
+	}
+
+	for _, it := range po.Items {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO purchase_order_items (po_id, product_id, quantity_ordered, unit_cost)
+			 VALUES (?, ?, ?, ?)`,
+			po.ID, it.ProductID, it.QuantityOrdered, it.UnitCost,
+		); err != nil {
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == 1452 {
+				return fmt.Errorf("product %d: %w", it.ProductID, ErrProductNotFound)
+			}
+			return fmt.Errorf("creating purchase order item: %w", err)
+		}
+	}
+
+	created, err := getPurchaseOrder(ctx, tx, po.ID, false)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing purchase order: %w", err)
+	}
+	*po = *created
+	return nil
+}
+
+func (r *mysqlPurchasingRepo) TransitionPurchaseOrder(ctx context.Context, id int64, to model.PurchaseOrderStatus) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning purchase order transition: %w", err)
+	}
+	defer tx.Rollback()
+
+	po, err := getPurchaseOrder(ctx, tx, id, true)
+	if err != nil {
+		return err
+	}
+	if !po.Status.CanTransitionTo(to) {
+		return fmt.Errorf("purchase order %d from %s to %s: %w", id, po.Status, to, ErrInvalidTransition)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE purchase_orders SET status = ? WHERE po_id = ?", to, id,
+	); err != nil {
+		return fmt.Errorf("updating purchase order %d: %w", id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing purchase order transition: %w", err)
+	}
+	return nil
+}
+
+// ReceivePurchaseOrder books arriving goods into the order's warehouse as
+// receipt movements and moves the order to partially_received or received.
+func (r *mysqlPurchasingRepo) ReceivePurchaseOrder(ctx context.Context, id int64, rcpt model.PurchaseOrderReceipt) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning purchase order receipt: %w", err)
+	}
+	defer tx.Rollback()
+
+	po, err := getPurchaseOrder(ctx, tx, id, true)
+	if err != nil {
+		return err
+	}
+	if !po.Status.CanTransitionTo(model.POPartiallyReceived) {
+		return fmt.Errorf("purchase order %d is %s: %w", id, po.Status, ErrInvalidTransition)
+	}
+
+	items := make(map[int64]*model.PurchaseOrderItem, len(po.Items))
+	for i := range po.Items {
+		items[po.Items[i].ProductID] = &po.Items[i]
+	}
+
+	for _, line := range rcpt.Lines {
+		it, ok := items[line.ProductID]
+		if !ok {
+			return fmt.Errorf("product %d on purchase order %d: %w", line.ProductID, id, ErrItemNotOnOrder)
+		}
+		if it.QuantityReceived+line.Quantity > it.QuantityOrdered {
+			return fmt.Errorf("product %d: %d received of %d ordered: %w",
+				line.ProductID, it.QuantityReceived+line.Quantity, it.QuantityOrdered, ErrOverReceipt)
+		}
+		it.QuantityReceived += line.Quantity
+
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE purchase_order_items SET quantity_received = ? WHERE item_id = ?",
+			it.QuantityReceived, it.ID,
+		); err != nil {
+			return fmt.Errorf("updating purchase order item %d: %w", it.ID, err)
+		}
+
+		m := &model.StockMovement{
+			ProductID:   line.ProductID,
+			WarehouseID: po.WarehouseID,
+			Type:        model.MovementReceipt,
+			Quantity:    line.Quantity,
+			Reason:      fmt.Sprintf("purchase order %d", id),
+			Actor:       rcpt.Actor,
+		}
+		if err := recordMovement(ctx, tx, m); err != nil {
+			return err
+		}
+	}
+
+	status := model.POReceived
+	for _, it := range po.Items {
+		if it.QuantityReceived < it.QuantityOrdered {
+			status = model.POPartiallyReceived
+			break
+		}
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE purchase_orders SET status = ? WHERE po_id = ?", status, id,
+	); err != nil {
+		return fmt.Errorf("updating purchase order %d: %w", id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing purchase order receipt: %w", err)
+	}
+	return nil
+}
+
+func getPurchaseOrder(ctx context.Context, q queryer, id int64, lock bool) (*model.PurchaseOrder, error) {
+	query := `SELECT po_id, supplier_id, warehouse_id, status, notes, created_at, updated_at
+	          FROM purchase_orders WHERE po_id = ?`
+	if lock {
+		query += " FOR UPDATE"
+	}
+
+	var po model.PurchaseOrder
+	err := q.QueryRowContext(ctx, query, id).Scan(
+		&po.ID, &po.SupplierID, &po.WarehouseID, &po.Status, &po.Notes, &po.CreatedAt, &po.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("purchase order %d: %w", id, ErrPurchaseOrderNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting purchase order %d: %w", id, err)
+	}
+
+	rows, err := q.QueryContext(ctx,
+		`SELECT item_id, product_id, quantity_ordered, quantity_received, unit_cost
+		 FROM purchase_order_items WHERE po_id = ? ORDER BY item_id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("listing items of purchase order %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	po.Items = []model.PurchaseOrderItem{}
+	for rows.Next() {
+		var it model.PurchaseOrderItem
+		if err := rows.Scan(&it.ID, &it.ProductID, &it.QuantityOrdered, &it.QuantityReceived, &it.UnitCost); err != nil {
+			return nil, fmt.Errorf("scanning purchase order item row: %w", err)
+		}
+		po.Items = append(po.Items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating purchase order item rows: %w", err)
+	}
+	return &po, nil
+}
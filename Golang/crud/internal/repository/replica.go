@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+type primaryKey struct{}
+
+// ReadPrimary returns a context whose reads skip the replicas, for callers
+// that must see a write they have just made.
+func ReadPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func readsPrimary(ctx context.Context) bool {
+	v, _ := ctx.Value(primaryKey{}).(bool)
+	return v
+}
+
+// ReadRouter sends read-only queries to replicas of the primary database,
+// taking turns among those that passed their last health check. Reads go to
+// the primary when no replica is healthy, and a read that fails on a replica
+// is retried there once while the replica is taken out of rotation until the
+// next check.
+type ReadRouter struct {
+	primary  *sql.DB
+	replicas []*replica
+	maxLag   time.Duration
+	next     atomic.Uint64
+	logger   *slog.Logger
+}
+
+type replica struct {
+	index   int
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// NewReadRouter routes reads over replicas, none of which is used until it
+// has passed a Check. With no replicas every read goes to the primary. A
+// maxLag of zero disables the replication lag check.
+func NewReadRouter(primary *sql.DB, replicas []*sql.DB, maxLag time.Duration, logger *slog.Logger) *ReadRouter {
+	r := &ReadRouter{primary: primary, maxLag: maxLag, logger: logger}
+	for i, db := range replicas {
+		r.replicas = append(r.replicas, &replica{index: i, db: db})
+	}
+	return r
+}
+
+// Close closes the replica connections; the primary belongs to the caller.
+func (r *ReadRouter) Close() error {
+	var errs []error
+	for _, rep := range r.replicas {
+		errs = append(errs, rep.db.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// read runs fn against a replica, or against the primary if none is usable
+// or the replica fails.
+func (r *ReadRouter) read(ctx context.Context, fn func(q queryer) error) error {
+	rep := r.pick(ctx)
+	if rep == nil {
+		return fn(r.primary)
+	}
+	err := fn(rep.db)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	if rep.healthy.CompareAndSwap(true, false) {
+		r.logger.Warn("replica_read_failed",
+			slog.Int("replica", rep.index),
+			slog.String("error", err.Error()),
+		)
+	}
+	return fn(r.primary)
+}
+
+func (r *ReadRouter) pick(ctx context.Context) *replica {
+	if len(r.replicas) == 0 || readsPrimary(ctx) {
+		return nil
+	}
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		rep := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if rep.healthy.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+// Check pings every replica and, when a maximum lag is set, compares it with
+// how far the replica's applier is behind, then puts the replica in or out
+// of rotation accordingly.
+func (r *ReadRouter) Check(ctx context.Context) {
+	for _, rep := range r.replicas {
+		err := r.checkReplica(ctx, rep.db)
+		healthy := err == nil
+		if rep.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			r.logger.Info("replica_healthy", slog.Int("replica", rep.index))
+		} else {
+			r.logger.Warn("replica_unhealthy",
+				slog.Int("replica", rep.index),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+func (r *ReadRouter) checkReplica(ctx context.Context, db *sql.DB) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		return err
+	}
+	if r.maxLag <= 0 {
+		return nil
+	}
+	lag, err := replicationLag(ctx, db)
+	if err != nil {
+		return err
+	}
+	if lag > r.maxLag {
+		return fmt.Errorf("replication lag %s exceeds %s", lag, r.maxLag)
+	}
+	return nil
+}
+
+// replicationLag reads Seconds_Behind_Source from SHOW REPLICA STATUS. A
+// server that is not replicating reports no lag.
+func replicationLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		return 0, fmt.Errorf("reading replica status: %w", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("reading replica status columns: %w", err)
+	}
+	if !rows.Next() {
+		return 0, rows.Err()
+	}
+	vals := make([]sql.NullString, len(cols))
+	dest := make([]any, len(cols))
+	for i := range vals {
+		dest[i] = &vals[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, fmt.Errorf("scanning replica status: %w", err)
+	}
+
+	for i, col := range cols {
+		if col != "Seconds_Behind_Source" {
+			continue
+		}
+		if !vals[i].Valid {
+			return 0, errors.New("replication is not running")
+		}
+		secs, err := strconv.Atoi(vals[i].String)
+		if err != nil {
+			return 0, fmt.Errorf("parsing replication lag %q: %w", vals[i].String, err)
+		}
+		return time.Duration(secs) * time.Second, nil
+	}
+	return 0, errors.New("replica status has no Seconds_Behind_Source")
+}
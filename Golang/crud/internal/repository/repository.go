@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type Repositories struct {
+	Products     ProductRepository
+	Reservations ReservationRepository
+	Warehouses   WarehouseRepository
+	Purchasing   PurchasingRepository
+	Orders       OrderRepository
+	Prices       PriceRepository
+	Images       ImageRepository
+	Categories   CategoryRepository
+	Outbox       OutboxRepository
+	Webhooks     WebhookRepository
+}
+
+// NewMySQLRepositories builds every repository on db. Product reads that can
+// tolerate replication lag are routed through reads.
+func NewMySQLRepositories(db *sql.DB, reads *ReadRouter) (*Repositories, error) {
+	var (
+		repos = &Repositories{}
+		err   error
+	)
+	fail := func(name string, err error) (*Repositories, error) {
+		repos.Close()
+		return nil, fmt.Errorf("%s repository: %w", name, err)
+	}
+
+	if repos.Products, err = NewMySQLProductRepo(db, reads); err != nil {
+		return fail("product", err)
+	}
+	if repos.Reservations, err = NewMySQLReservationRepo(db); err != nil {
+		return fail("reservation", err)
+	}
+	if repos.Warehouses, err = NewMySQLWarehouseRepo(db); err != nil {
+		return fail("warehouse", err)
+	}
+	if repos.Purchasing, err = NewMySQLPurchasingRepo(db); err != nil {
+		return fail("purchasing", err)
+	}
+	if repos.Orders, err = NewMySQLOrderRepo(db); err != nil {
+		return fail("order", err)
+	}
+	if repos.Prices, err = NewMySQLPriceRepo(db); err != nil {
+		return fail("price", err)
+	}
+	if repos.Images, err = NewMySQLImageRepo(db); err != nil {
+		return fail("image", err)
+	}
+	if repos.Categories, err = NewMySQLCategoryRepo(db); err != nil {
+		return fail("category", err)
+	}
+	if repos.Outbox, err = NewMySQLOutboxRepo(db); err != nil {
+		return fail("outbox", err)
+	}
+	if repos.Webhooks, err = NewMySQLWebhookRepo(db); err != nil {
+		return fail("webhook", err)
+	}
+	return repos, nil
+}
+
+func (r *Repositories) Close() error {
+	var errs []error
+	for _, c := range []io.Closer{r.Products, r.Reservations, r.Warehouses, r.Purchasing, r.Orders, r.Prices, r.Images, r.Categories, r.Outbox, r.Webhooks} {
+		if c != nil {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"golang-sql/internal/model"
+)
+
+var (
+	ErrReservationNotFound  = errors.New("reservation not found")
+	ErrReservationNotActive = errors.New("reservation is not active")
+)
+
+type ReservationRepository interface {
+	Create(ctx context.Context, res *model.Reservation) error
+	GetByID(ctx context.Context, id int64) (*model.Reservation, error)
+	Commit(ctx context.Context, id int64, c model.ReservationCommit) (*model.StockMovement, error)
+	Release(ctx context.Context, id int64) error
+	ReleaseExpired(ctx context.Context) (int, error)
+	Close() error
+}
+
+type mysqlReservationRepo struct {
+	db          *sql.DB
+	stmtGetByID *sql.Stmt
+	stmtReserve *sql.Stmt
+}
+
+func NewMySQLReservationRepo(db *sql.DB) (ReservationRepository, error) {
+	getByID, err := db.Prepare(`SELECT reservation_id, product_id, quantity, status, expires_at, created_at
+	                            FROM reservations WHERE reservation_id = ?`)
+	if err != nil {
+		return nil, fmt.Errorf("preparing getByID: %w", err)
+	}
+	reserve, err := db.Prepare(`UPDATE products
+	                            SET reserved_quantity = reserved_quantity + ?
+	                            WHERE product_id = ? AND stock_quantity - reserved_quantity >= ?`)
+	if err != nil {
+		getByID.Close()
+		return nil, fmt.Errorf("preparing reserve: %w", err)
+	}
+
+	return &mysqlReservationRepo{db: db, stmtGetByID: getByID, stmtReserve: reserve}, nil
+}
+
+func (r *mysqlReservationRepo) Close() error {
+	r.stmtGetByID.Close()
+	r.stmtReserve.Close()
+	return nil
+}
+
+func (r *mysqlReservationRepo) Create(ctx context.Context, res *model.Reservation) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning reservation: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status model.ProductStatus
+	err = tx.QueryRowContext(ctx,
+		"SELECT status FROM products WHERE product_id = ? FOR UPDATE", res.ProductID,
+	).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("product %d: %w", res.ProductID, ErrProductNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("locking product %d: %w", res.ProductID, err)
+	}
+	if !status.Sellable() {
+		return fmt.Errorf("product %d is %s: %w", res.ProductID, status, ErrProductNotForSale)
+	}
+
+	result, err := tx.StmtContext(ctx, r.stmtReserve).ExecContext(ctx, res.Quantity, res.ProductID, res.Quantity)
+	if err != nil {
+		return fmt.Errorf("reserving stock for product %d: %w", res.ProductID, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking rows affected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("product %d cannot reserve %d: %w", res.ProductID, res.Quantity, ErrInsufficientStock)
+	}
+
+	result, err = tx.ExecContext(ctx,
+		`INSERT INTO reservations (product_id, quantity, status, expires_at)
+		 VALUES (?, ?, 'active', NOW() + INTERVAL ? SECOND)`,
+		res.ProductID, res.Quantity, res.TTLSeconds,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting reservation: %w", err)
+	}
+	if res.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("getting last insert id: %w", err)
+	}
+	if err := tx.StmtContext(ctx, r.stmtGetByID).QueryRowContext(ctx, res.ID).Scan(
+		&res.ID, &res.ProductID, &res.Quantity, &res.Status, &res.ExpiresAt, &res.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("reading reservation %d: %w", res.ID, err)
+	}
+	if err := recordEvent(ctx, tx, model.EventProductUpdated, res.ProductID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing reservation: %w", err)
+	}
+	return nil
+}
+
+func (r *mysqlReservationRepo) GetByID(ctx context.Context, id int64) (*model.Reservation, error) {
+	var res model.Reservation
+	err := r.stmtGetByID.QueryRowContext(ctx, id).Scan(
+		&res.ID, &res.ProductID, &res.Quantity, &res.Status, &res.ExpiresAt, &res.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting reservation %d: %w", id, err)
+	}
+	return &res, nil
+}
+
+func (r *mysqlReservationRepo) Commit(ctx context.Context, id int64, c model.ReservationCommit) (*model.StockMovement, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("beginning reservation commit: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := lockActiveReservation(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var stock int
+	if err := tx.QueryRowContext(ctx,
+		"SELECT stock_quantity FROM products WHERE product_id = ? FOR UPDATE", res.ProductID,
+	).Scan(&stock); err != nil {
+		return nil, fmt.Errorf("locking product %d: %w", res.ProductID, err)
+	}
+
+	m := &model.StockMovement{
+		ProductID:   res.ProductID,
+		WarehouseID: c.WarehouseID,
+		Type:        model.MovementSale,
+		Quantity:    -res.Quantity,
+		StockAfter:  stock - res.Quantity,
+		Reason:      c.Reason,
+		Actor:       c.Actor,
+	}
+	if m.Reason == "" {
+		m.Reason = fmt.Sprintf("reservation %d committed", id)
+	}
+
+	if err := applyLocationDelta(ctx, tx, m.WarehouseID, m.ProductID, m.Quantity); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE products
+		 SET stock_quantity = ?, reserved_quantity = reserved_quantity - ?
+		 WHERE product_id = ?`,
+		m.StockAfter, res.Quantity, res.ProductID,
+	); err != nil {
+		return nil, fmt.Errorf("deducting stock for product %d: %w", res.ProductID, err)
+	}
+	if err := recordStockChange(ctx, tx, res.ProductID, stock, m.StockAfter); err != nil {
+		return nil, err
+	}
+	if err := insertMovement(ctx, tx, m); err != nil {
+		return nil, err
+	}
+	if err := setReservationStatus(ctx, tx, id, model.ReservationCommitted); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing reservation %d: %w", id, err)
+	}
+	return m, nil
+}
+
+func (r *mysqlReservationRepo) Release(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning reservation release: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := lockActiveReservation(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := releaseReservation(ctx, tx, res, model.ReservationReleased); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing reservation release %d: %w", id, err)
+	}
+	return nil
+}
+
+func (r *mysqlReservationRepo) ReleaseExpired(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("beginning expiry sweep: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT reservation_id, product_id, quantity
+		 FROM reservations
+		 WHERE status = 'active' AND expires_at <= NOW()
+		 ORDER BY reservation_id
+		 LIMIT 500
+		 FOR UPDATE SKIP LOCKED`,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("selecting expired reservations: %w", err)
+	}
+	var expired []model.Reservation
+	for rows.Next() {
+		var res model.Reservation
+		if err := rows.Scan(&res.ID, &res.ProductID, &res.Quantity); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning expired reservation row: %w", err)
+		}
+		expired = append(expired, res)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterating expired reservation rows: %w", err)
+	}
+
+	for i := range expired {
+		if err := releaseReservation(ctx, tx, &expired[i], model.ReservationExpired); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing expiry sweep: %w", err)
+	}
+	return len(expired), nil
+}
+
+func lockActiveReservation(ctx context.Context, tx *sql.Tx, id int64) (*model.Reservation, error) {
+	var (
+		res     model.Reservation
+		expired bool
+	)
+	err := tx.QueryRowContext(ctx,
+		`SELECT reservation_id, product_id, quantity, status, expires_at, created_at, expires_at <= NOW()
+		 FROM reservations WHERE reservation_id = ? FOR UPDATE`, id,
+	).Scan(&res.ID, &res.ProductID, &res.Quantity, &res.Status, &res.ExpiresAt, &res.CreatedAt, &expired)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("reservation %d: %w", id, ErrReservationNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("locking reservation %d: %w", id, err)
+	}
+
+	if res.Status != model.ReservationActive {
+		return nil, fmt.Errorf("reservation %d is %s: %w", id, res.Status, ErrReservationNotActive)
+	}
+	if expired {
+		return nil, fmt.Errorf("reservation %d has expired: %w", id, ErrReservationNotActive)
+	}
+	return &res, nil
+}
+
+func releaseReservation(ctx context.Context, tx *sql.Tx, res *model.Reservation, status model.ReservationStatus) error {
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE products SET reserved_quantity = reserved_quantity - ? WHERE product_id = ?",
+		res.Quantity, res.ProductID,
+	); err != nil {
+		return fmt.Errorf("releasing stock for product %d: %w", res.ProductID, err)
+	}
+	if err := recordEvent(ctx, tx, model.EventProductUpdated, res.ProductID); err != nil {
+		return err
+	}
+	return setReservationStatus(ctx, tx, res.ID, status)
+}
+
+func setReservationStatus(ctx context.Context, tx *sql.Tx, id int64, status model.ReservationStatus) error {
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE reservations SET status = ? WHERE reservation_id = ?", status, id,
+	); err != nil {
+		return fmt.Errorf("marking reservation %d %s: %w", id, status, err)
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang-sql/internal/model"
+)
+
+// systemActor is the actor of stock movements the service books itself, such
+// as a new product's initial stock.
+const systemActor = "system"
+
+func (r *mysqlProductRepo) RecordMovement(ctx context.Context, m *model.StockMovement) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning stock movement: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := recordMovement(ctx, tx.Tx, m); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing stock movement: %w", err)
+	}
+	return nil
+}
+
+// recordMovement applies m to the product aggregate and its warehouse and
+// appends it to the ledger, all inside the caller's transaction. Movements on
+// a bundle are booked against its components instead.
+func recordMovement(ctx context.Context, tx *sql.Tx, m *model.StockMovement) error {
+	var (
+		current, reserved int
+		isBundle          bool
+	)
+	err := tx.QueryRowContext(ctx,
+		"SELECT stock_quantity, reserved_quantity, is_bundle FROM products WHERE product_id = ? FOR UPDATE", m.ProductID,
+	).Scan(&current, &reserved, &isBundle)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("product %d: %w", m.ProductID, ErrProductNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("locking product %d: %w", m.ProductID, err)
+	}
+	if isBundle {
+		return recordBundleMovement(ctx, tx, m)
+	}
+
+	m.StockAfter = current + m.Quantity
+	if m.StockAfter < 0 {
+		return fmt.Errorf("product %d has %d on hand: %w", m.ProductID, current, ErrInsufficientStock)
+	}
+	// Reserved units are spoken for, so nothing but committing the
+	// reservation may take them.
+	if m.Quantity < 0 && m.StockAfter < reserved {
+		return fmt.Errorf("product %d has %d available: %w", m.ProductID, current-reserved, ErrInsufficientStock)
+	}
+
+	if err := applyLocationDelta(ctx, tx, m.WarehouseID, m.ProductID, m.Quantity); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE products SET stock_quantity = ? WHERE product_id = ?", m.StockAfter, m.ProductID,
+	); err != nil {
+		return fmt.Errorf("updating stock for product %d: %w", m.ProductID, err)
+	}
+	if err := recordStockChange(ctx, tx, m.ProductID, current, m.StockAfter); err != nil {
+		return err
+	}
+
+	return insertMovement(ctx, tx, m)
+}
+
+// lockProducts locks the given products, and the components of any bundles
+// among them, in ascending ID order and returns those that exist by ID. Taking
+// every lock up front in one order means two transactions over the same
+// products queue behind each other instead of each holding a lock the other
+// is waiting on.
+func lockProducts(ctx context.Context, tx *sql.Tx, ids []int64) (map[int64]model.Product, error) {
+	if len(ids) == 0 {
+		return map[int64]model.Product{}, nil
+	}
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	in := `(?` + strings.Repeat(", ?", len(ids)-1) + `)`
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT DISTINCT component_id FROM bundle_components WHERE bundle_id IN "+in, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing bundle components: %w", err)
+	}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning bundle component row: %w", err)
+		}
+		args = append(args, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating bundle component rows: %w", err)
+	}
+
+	rows, err = tx.QueryContext(ctx,
+		`SELECT product_id, name, price, status FROM products
+		 WHERE product_id IN (?`+strings.Repeat(", ?", len(args)-1)+`)
+		 ORDER BY product_id FOR UPDATE`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("locking products: %w", err)
+	}
+	defer rows.Close()
+
+	products := make(map[int64]model.Product, len(args))
+	for rows.Next() {
+		var p model.Product
+		if err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Status); err != nil {
+			return nil, fmt.Errorf("scanning product row: %w", err)
+		}
+		products[p.ID] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating product rows: %w", err)
+	}
+	return products, nil
+}
+
+func (r *mysqlProductRepo) ListMovements(ctx context.Context, productID int64, limit int) ([]model.StockMovement, error) {
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	rows, err := r.stmt(ctx, r.stmtListMovements).QueryContext(ctx, productID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing stock movements for product %d: %w", productID, err)
+	}
+	defer rows.Close()
+
+	movements := make([]model.StockMovement, 0, limit)
+	for rows.Next() {
+		var m model.StockMovement
+		if err := rows.Scan(&m.ID, &m.ProductID, &m.WarehouseID, &m.Type, &m.Quantity, &m.StockAfter, &m.Reason, &m.Actor, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning stock movement row: %w", err)
+		}
+		movements = append(movements, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating stock movement rows: %w", err)
+	}
+	return movements, nil
+}
+
+func (r *mysqlProductRepo) AdjustStock(ctx context.Context, productID int64, a model.StockAdjustment) (*model.StockMovement, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("beginning stock adjustment: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.StmtContext(ctx, r.stmtAdjustStock).ExecContext(ctx, a.Delta, productID, a.Delta, a.Delta)
+	if err != nil {
+		return nil, fmt.Errorf("adjusting stock for product %d: %w", productID, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("checking rows affected: %w", err)
+	}
+	if affected == 0 {
+		var isBundle bool
+		err := tx.QueryRowContext(ctx, "SELECT is_bundle FROM products WHERE product_id = ?", productID).Scan(&isBundle)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("product %d: %w", productID, ErrProductNotFound)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("checking product %d: %w", productID, err)
+		}
+		if isBundle {
+			return nil, fmt.Errorf("product %d: %w", productID, ErrBundleStock)
+		}
+		return nil, fmt.Errorf("product %d has too little unreserved stock for delta %d: %w", productID, a.Delta, ErrInsufficientStock)
+	}
+
+	if err := applyLocationDelta(ctx, tx.Tx, a.WarehouseID, productID, a.Delta); err != nil {
+		return nil, err
+	}
+
+	m := &model.StockMovement{
+		ProductID:   productID,
+		WarehouseID: a.WarehouseID,
+		Type:        model.MovementAdjustment,
+		Quantity:    a.Delta,
+		Reason:      a.Reason,
+		Actor:       a.Actor,
+	}
+	if err := tx.QueryRowContext(ctx,
+		"SELECT stock_quantity FROM products WHERE product_id = ?", productID,
+	).Scan(&m.StockAfter); err != nil {
+		return nil, fmt.Errorf("reading stock for product %d: %w", productID, err)
+	}
+	if err := recordStockChange(ctx, tx.Tx, productID, m.StockAfter-a.Delta, m.StockAfter); err != nil {
+		return nil, err
+	}
+
+	if err := insertMovement(ctx, tx.Tx, m); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing stock adjustment: %w", err)
+	}
+	return m, nil
+}
+
+// applyLocationDelta changes the quantity held at a single warehouse. It
+// refuses to take a location below zero; the caller is responsible for
+// keeping products.stock_quantity in step.
+func applyLocationDelta(ctx context.Context, tx *sql.Tx, warehouseID, productID int64, delta int) error {
+	if err := requireWarehouse(ctx, tx, warehouseID); err != nil {
+		return err
+	}
+
+	if delta >= 0 {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO warehouse_stock (warehouse_id, product_id, quantity) VALUES (?, ?, ?)
+			 ON DUPLICATE KEY UPDATE quantity = quantity + VALUES(quantity)`,
+			warehouseID, productID, delta,
+		); err != nil {
+			return fmt.Errorf("adding stock at warehouse %d: %w", warehouseID, err)
+		}
+		return nil
+	}
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE warehouse_stock SET quantity = quantity + ?
+		 WHERE warehouse_id = ? AND product_id = ? AND quantity + ? >= 0`,
+		delta, warehouseID, productID, delta,
+	)
+	if err != nil {
+		return fmt.Errorf("removing stock at warehouse %d: %w", warehouseID, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking rows affected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("warehouse %d cannot supply %d of product %d: %w", warehouseID, -delta, productID, ErrInsufficientStock)
+	}
+	return nil
+}
+
+func requireWarehouse(ctx context.Context, q queryer, warehouseID int64) error {
+	var exists int
+	err := q.QueryRowContext(ctx, "SELECT 1 FROM warehouses WHERE warehouse_id = ?", warehouseID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("warehouse %d: %w", warehouseID, ErrWarehouseNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("checking warehouse %d: %w", warehouseID, err)
+	}
+	return nil
+}
+
+func insertMovement(ctx context.Context, tx *sql.Tx, m *model.StockMovement) error {
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO stock_movements (product_id, warehouse_id, movement_type, quantity, stock_after, reason, actor)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		m.ProductID, m.WarehouseID, m.Type, m.Quantity, m.StockAfter, m.Reason, m.Actor,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting stock movement: %w", err)
+	}
+	if m.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("getting last insert id: %w", err)
+	}
+	if err := tx.QueryRowContext(ctx,
+		"SELECT created_at FROM stock_movements WHERE movement_id = ?", m.ID,
+	).Scan(&m.CreatedAt); err != nil {
+		return fmt.Errorf("reading stock movement %d: %w", m.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// WithTx runs fn with a ProductRepository bound to a single transaction,
+// committing it if fn returns nil and rolling it back otherwise, so several
+// calls can take effect together. Calls that would otherwise run in a
+// transaction of their own run in a savepoint instead: one that fails is
+// undone without aborting the rest, leaving fn to decide whether to carry on.
+// Reads on tx see its uncommitted writes and never go to a replica. Calling
+// WithTx on tx nests a unit of work in a savepoint.
+//
+// tx must not be used after fn returns, nor from several goroutines at once.
+func (r *mysqlProductRepo) WithTx(ctx context.Context, fn func(tx ProductRepository) error) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning unit of work: %w", err)
+	}
+	defer tx.Rollback()
+
+	bound := *r
+	bound.tx = tx.Tx
+	if bound.savepoints == nil {
+		bound.savepoints = new(int)
+	}
+	if err := fn(&bound); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing unit of work: %w", err)
+	}
+	return nil
+}
+
+// txn is the transaction a repository call runs in. Outside a unit of work it
+// is a transaction of its own; inside one it is a savepoint in the unit's
+// transaction, which Commit releases and Rollback returns to.
+type txn struct {
+	*sql.Tx
+	ctx       context.Context
+	savepoint string
+	done      bool
+}
+
+func (t *txn) Commit() error {
+	if t.savepoint == "" {
+		return t.Tx.Commit()
+	}
+	if _, err := t.Tx.ExecContext(t.ctx, "RELEASE SAVEPOINT "+t.savepoint); err != nil {
+		return err
+	}
+	t.done = true
+	return nil
+}
+
+func (t *txn) Rollback() error {
+	if t.savepoint == "" {
+		return t.Tx.Rollback()
+	}
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	_, err := t.Tx.ExecContext(t.ctx, "ROLLBACK TO SAVEPOINT "+t.savepoint)
+	return err
+}
+
+func (r *mysqlProductRepo) begin(ctx context.Context) (*txn, error) {
+	if r.tx == nil {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &txn{Tx: tx}, nil
+	}
+
+	*r.savepoints++
+	name := fmt.Sprintf("sp%d", *r.savepoints)
+	if _, err := r.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	return &txn{Tx: r.tx, ctx: ctx, savepoint: name}, nil
+}
+
+// conn is what reads outside a transaction run on: the unit of work's
+// transaction if there is one, otherwise the primary.
+func (r *mysqlProductRepo) conn() queryer {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// stmt binds a prepared statement to the unit of work's transaction, if any.
+func (r *mysqlProductRepo) stmt(ctx context.Context, s *sql.Stmt) *sql.Stmt {
+	if r.tx != nil {
+		return r.tx.StmtContext(ctx, s)
+	}
+	return s
+}
+
+// read runs fn on the unit of work's transaction, if any, and otherwise
+// lets the read router choose where it runs.
+func (r *mysqlProductRepo) read(ctx context.Context, fn func(q queryer) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+	return r.reads.read(ctx, fn)
+}
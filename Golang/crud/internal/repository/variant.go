@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang-sql/internal/model"
+)
+
+var (
+	ErrSKUTaken      = errors.New("sku already in use")
+	ErrInvalidParent = errors.New("variants can only belong to a top-level product")
+)
+
+func (r *mysqlProductRepo) ListVariants(ctx context.Context, parentID int64) ([]model.Product, error) {
+	var exists int
+	err := r.conn().QueryRowContext(ctx, "SELECT 1 FROM products WHERE product_id = ?", parentID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("product %d: %w", parentID, ErrProductNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("checking product %d: %w", parentID, err)
+	}
+
+	rows, err := r.conn().QueryContext(ctx,
+		`SELECT `+productColumns+`
+		 FROM products WHERE parent_id = ? ORDER BY product_id`, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("listing variants of product %d: %w", parentID, err)
+	}
+	defer rows.Close()
+
+	variants := []model.Product{}
+	for rows.Next() {
+		var v model.Product
+		if err := scanProduct(rows, &v); err != nil {
+			return nil, fmt.Errorf("scanning variant row: %w", err)
+		}
+		variants = append(variants, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating variant rows: %w", err)
+	}
+	if err := attachDetails(ctx, r.conn(), variants); err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+// attachVariants loads the variants of every product in parents with one
+// query, keeping only those in status when it is set.
+func attachVariants(ctx context.Context, q queryer, parents []model.Product, status model.ProductStatus) error {
+	if len(parents) == 0 {
+		return nil
+	}
+
+	index := make(map[int64]int, len(parents))
+	args := make([]interface{}, 0, len(parents)+1)
+	for i, p := range parents {
+		index[p.ID] = i
+		args = append(args, p.ID)
+	}
+	query := `SELECT ` + productColumns + `
+	          FROM products WHERE parent_id IN (?` + strings.Repeat(", ?", len(parents)-1) + `)`
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY product_id"
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("listing variants: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v model.Product
+		if err := scanProduct(rows, &v); err != nil {
+			return fmt.Errorf("scanning variant row: %w", err)
+		}
+		parent := &parents[index[*v.ParentID]]
+		parent.Variants = append(parent.Variants, v)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating variant rows: %w", err)
+	}
+	return nil
+}
+
+// variantPrice locks the parent of a variant and returns the price the
+// variant sells at: its override if it has one, otherwise the parent's price.
+func variantPrice(ctx context.Context, tx *sql.Tx, parentID int64, override *float64) (float64, error) {
+	var (
+		price       float64
+		grandparent sql.NullInt64
+	)
+	err := tx.QueryRowContext(ctx,
+		"SELECT price, parent_id FROM products WHERE product_id = ? FOR UPDATE", parentID,
+	).Scan(&price, &grandparent)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("parent product %d: %w", parentID, ErrProductNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("locking parent product %d: %w", parentID, err)
+	}
+	if grandparent.Valid {
+		return 0, fmt.Errorf("product %d is itself a variant: %w", parentID, ErrInvalidParent)
+	}
+
+	if override != nil {
+		return *override, nil
+	}
+	return price, nil
+}
+
+// followParentPrice passes a new parent price on to every variant that has no
+// price override, recording the change in each variant's price history.
+func followParentPrice(ctx context.Context, tx *sql.Tx, parentID int64, price float64, source model.PriceSource, actor string) error {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT product_id FROM products
+		 WHERE parent_id = ? AND price_override IS NULL AND price <> ?
+		 FOR UPDATE`, parentID, price)
+	if err != nil {
+		return fmt.Errorf("locking variants of product %d: %w", parentID, err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning variant id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating variant ids: %w", err)
+	}
+
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE products SET price = ? WHERE product_id = ?", price, id,
+		); err != nil {
+			return fmt.Errorf("updating price of variant %d: %w", id, err)
+		}
+		if err := recordPriceChange(ctx, tx, id, price, source, actor); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, tx, model.EventProductUpdated, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
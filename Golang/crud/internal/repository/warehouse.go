@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+
+	"golang-sql/internal/model"
+)
+
+var ErrWarehouseCodeTaken = errors.New("warehouse code already exists")
+
+type WarehouseRepository interface {
+	List(ctx context.Context) ([]model.Warehouse, error)
+	Create(ctx context.Context, w *model.Warehouse) error
+	StockByWarehouse(ctx context.Context, warehouseID int64) ([]model.StockLevel, error)
+	StockByProduct(ctx context.Context, productID int64) ([]model.StockLevel, error)
+	Transfer(ctx context.Context, t model.StockTransfer) ([]model.StockMovement, error)
+	Close() error
+}
+
+type mysqlWarehouseRepo struct {
+	db *sql.DB
+}
+
+func NewMySQLWarehouseRepo(db *sql.DB) (WarehouseRepository, error) {
+	return &mysqlWarehouseRepo{db: db}, nil
+}
+
+func (r *mysqlWarehouseRepo) Close() error {
+	return nil
+}
+
+func (r *mysqlWarehouseRepo) List(ctx context.Context) ([]model.Warehouse, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT warehouse_id, code, name, created_at FROM warehouses ORDER BY warehouse_id")
+	if err != nil {
+		return nil, fmt.Errorf("listing warehouses: %w", err)
+	}
+	defer rows.Close()
+
+	warehouses := []model.Warehouse{}
+	for rows.Next() {
+		var w model.Warehouse
+		if err := rows.Scan(&w.ID, &w.Code, &w.Name, &w.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning warehouse row: %w", err)
+		}
+		warehouses = append(warehouses, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating warehouse rows: %w", err)
+	}
+	return warehouses, nil
+}
+
+func (r *mysqlWarehouseRepo) Create(ctx context.Context, w *model.Warehouse) error {
+	result, err := r.db.ExecContext(ctx, "INSERT INTO warehouses (code, name) VALUES (?, ?)", w.Code, w.Name)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return fmt.Errorf("warehouse %q: %w", w.Code, ErrWarehouseCodeTaken)
+		}
+		return fmt.Errorf("creating warehouse: %w", err)
+	}
+	if w.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("getting last insert id: %w", err)
+	}
+	if err := r.db.QueryRowContext(ctx,
+		"SELECT created_at FROM warehouses WHERE warehouse_id = ?", w.ID,
+	).Scan(&w.CreatedAt); err != nil {
+		return fmt.Errorf("reading warehouse %d: %w", w.ID, err)
+	}
+	return nil
+}
+
+func (r *mysqlWarehouseRepo) StockByWarehouse(ctx context.Context, warehouseID int64) ([]model.StockLevel, error) {
+	var exists int
+	err := r.db.QueryRowContext(ctx, "SELECT 1 FROM warehouses WHERE warehouse_id = ?", warehouseID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("warehouse %d: %w", warehouseID, ErrWarehouseNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("checking warehouse %d: %w", warehouseID, err)
+	}
+
+	return r.queryLevels(ctx, `
+		SELECT w.warehouse_id, w.code, p.product_id, p.name, ws.quantity
+		FROM warehouse_stock ws
+		JOIN warehouses w ON w.warehouse_id = ws.warehouse_id
+		JOIN products p ON p.product_id = ws.product_id
+		WHERE ws.warehouse_id = ? AND ws.quantity > 0
+		ORDER BY p.name`, warehouseID)
+}
+
+func (r *mysqlWarehouseRepo) StockByProduct(ctx context.Context, productID int64) ([]model.StockLevel, error) {
+	return r.queryLevels(ctx, `
+		SELECT w.warehouse_id, w.code, p.product_id, p.name, ws.quantity
+		FROM warehouse_stock ws
+		JOIN warehouses w ON w.warehouse_id = ws.warehouse_id
+		JOIN products p ON p.product_id = ws.product_id
+		WHERE ws.product_id = ?
+		ORDER BY w.warehouse_id`, productID)
+}
+
+func (r *mysqlWarehouseRepo) queryLevels(ctx context.Context, query string, args ...interface{}) ([]model.StockLevel, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing stock levels: %w", err)
+	}
+	defer rows.Close()
+
+	levels := []model.StockLevel{}
+	for rows.Next() {
+		var l model.StockLevel
+		if err := rows.Scan(&l.WarehouseID, &l.WarehouseCode, &l.ProductID, &l.ProductName, &l.Quantity); err != nil {
+			return nil, fmt.Errorf("scanning stock level row: %w", err)
+		}
+		levels = append(levels, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating stock level rows: %w", err)
+	}
+	return levels, nil
+}
+
+// Transfer moves stock between two locations. The product's total is
+// unchanged; the ledger gets one outgoing and one incoming entry, and a
+// product.updated event is recorded for the new per-warehouse stock.
+func (r *mysqlWarehouseRepo) Transfer(ctx context.Context, t model.StockTransfer) ([]model.StockMovement, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("beginning stock transfer: %w", err)
+	}
+	defer tx.Rollback()
+
+	var stock int
+	err = tx.QueryRowContext(ctx,
+		"SELECT stock_quantity FROM products WHERE product_id = ? FOR UPDATE", t.ProductID,
+	).Scan(&stock)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("product %d: %w", t.ProductID, ErrProductNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("locking product %d: %w", t.ProductID, err)
+	}
+
+	if t.Reason == "" {
+		t.Reason = fmt.Sprintf("transfer from warehouse %d to %d", t.FromWarehouseID, t.ToWarehouseID)
+	}
+	movements := []model.StockMovement{
+		{WarehouseID: t.FromWarehouseID, Quantity: -t.Quantity},
+		{WarehouseID: t.ToWarehouseID, Quantity: t.Quantity},
+	}
+	for i := range movements {
+		m := &movements[i]
+		m.ProductID = t.ProductID
+		m.Type = model.MovementTransfer
+		m.StockAfter = stock
+		m.Reason = t.Reason
+		m.Actor = t.Actor
+
+		if err := applyLocationDelta(ctx, tx, m.WarehouseID, m.ProductID, m.Quantity); err != nil {
+			return nil, err
+		}
+		if err := insertMovement(ctx, tx, m); err != nil {
+			return nil, err
+		}
+	}
+	if err := touchProduct(ctx, tx, t.ProductID); err != nil {
+		return nil, err
+	}
+	if err := recordEvent(ctx, tx, model.EventProductUpdated, t.ProductID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing stock transfer: %w", err)
+	}
+	return movements, nil
+}
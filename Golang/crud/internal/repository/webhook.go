@@ -0,0 +1,358 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang-sql/internal/model"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+type WebhookRepository interface {
+	List(ctx context.Context) ([]model.WebhookSubscription, error)
+	GetByID(ctx context.Context, id int64) (*model.WebhookSubscription, error)
+	Create(ctx context.Context, s *model.WebhookSubscription) error
+	Update(ctx context.Context, s *model.WebhookSubscription) error
+	Delete(ctx context.Context, id int64) error
+	Enqueue(ctx context.Context, e model.Event) (int64, error)
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookAttempt, error)
+	MarkDelivered(ctx context.Context, id int64, responseStatus int) error
+	MarkFailed(ctx context.Context, id int64, responseStatus int, reason string, retryAt *time.Time) error
+	ListDeliveries(ctx context.Context, subscriptionID int64, status model.WebhookDeliveryStatus, limit int) ([]model.WebhookDelivery, error)
+	Redeliver(ctx context.Context, id int64) error
+	Close() error
+}
+
+type mysqlWebhookRepo struct {
+	db *sql.DB
+}
+
+func NewMySQLWebhookRepo(db *sql.DB) (WebhookRepository, error) {
+	return &mysqlWebhookRepo{db: db}, nil
+}
+
+func (r *mysqlWebhookRepo) Close() error {
+	return nil
+}
+
+const subscriptionColumns = `subscription_id, url, events, active, created_at, updated_at`
+
+func scanSubscription(row rowScanner, s *model.WebhookSubscription) error {
+	var events []byte
+	if err := row.Scan(&s.ID, &s.URL, &events, &s.Active, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(events, &s.Events); err != nil {
+		return fmt.Errorf("decoding events of webhook %d: %w", s.ID, err)
+	}
+	return nil
+}
+
+func (r *mysqlWebhookRepo) List(ctx context.Context) ([]model.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+subscriptionColumns+" FROM webhook_subscriptions ORDER BY subscription_id")
+	if err != nil {
+		return nil, fmt.Errorf("listing webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []model.WebhookSubscription{}
+	for rows.Next() {
+		var s model.WebhookSubscription
+		if err := scanSubscription(rows, &s); err != nil {
+			return nil, fmt.Errorf("scanning webhook row: %w", err)
+		}
+		subs = append(subs, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating webhook rows: %w", err)
+	}
+	return subs, nil
+}
+
+func (r *mysqlWebhookRepo) GetByID(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	var s model.WebhookSubscription
+	err := scanSubscription(r.db.QueryRowContext(ctx,
+		"SELECT "+subscriptionColumns+" FROM webhook_subscriptions WHERE subscription_id = ?", id,
+	), &s)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting webhook %d: %w", id, err)
+	}
+	return &s, nil
+}
+
+// Create stores s, whose secret must already be set, and fills in its ID and
+// timestamps.
+func (r *mysqlWebhookRepo) Create(ctx context.Context, s *model.WebhookSubscription) error {
+	events, err := json.Marshal(s.Events)
+	if err != nil {
+		return fmt.Errorf("encoding events: %w", err)
+	}
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO webhook_subscriptions (url, secret, events, active) VALUES (?, ?, ?, ?)",
+		s.URL, s.Secret, events, s.Active,
+	)
+	if err != nil {
+		return fmt.Errorf("creating webhook: %w", err)
+	}
+	if s.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("getting last insert id: %w", err)
+	}
+	if err := r.db.QueryRowContext(ctx,
+		"SELECT created_at, updated_at FROM webhook_subscriptions WHERE subscription_id = ?", s.ID,
+	).Scan(&s.CreatedAt, &s.UpdatedAt); err != nil {
+		return fmt.Errorf("reading webhook %d: %w", s.ID, err)
+	}
+	return nil
+}
+
+// Update changes the URL, event types and active flag of s. The secret is
+// kept unless s carries a new one.
+func (r *mysqlWebhookRepo) Update(ctx context.Context, s *model.WebhookSubscription) error {
+	events, err := json.Marshal(s.Events)
+	if err != nil {
+		return fmt.Errorf("encoding events: %w", err)
+	}
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_subscriptions
+		 SET url = ?, events = ?, active = ?, secret = IF(? = '', secret, ?)
+		 WHERE subscription_id = ?`,
+		s.URL, events, s.Active, s.Secret, s.Secret, s.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating webhook %d: %w", s.ID, err)
+	}
+	// MySQL reports no affected rows for an update that changes nothing, so
+	// existence is checked separately.
+	if n, err := result.RowsAffected(); err == nil && n > 0 {
+		return nil
+	}
+	var exists int
+	err = r.db.QueryRowContext(ctx, "SELECT 1 FROM webhook_subscriptions WHERE subscription_id = ?", s.ID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("webhook %d: %w", s.ID, ErrWebhookNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("checking webhook %d: %w", s.ID, err)
+	}
+	return nil
+}
+
+// Delete removes the subscription along with its delivery log.
+func (r *mysqlWebhookRepo) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM webhook_subscriptions WHERE subscription_id = ?", id)
+	if err != nil {
+		return fmt.Errorf("deleting webhook %d: %w", id, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking rows affected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("webhook %d: %w", id, ErrWebhookNotFound)
+	}
+	return nil
+}
+
+// Enqueue creates a pending delivery of e for every active subscription to
+// its type and returns how many it created. Enqueueing an event twice has no
+// further effect.
+func (r *mysqlWebhookRepo) Enqueue(ctx context.Context, e model.Event) (int64, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return 0, fmt.Errorf("encoding event %d: %w", e.ID, err)
+	}
+	result, err := r.db.ExecContext(ctx,
+		`INSERT IGNORE INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		 SELECT subscription_id, ?, ?, ? FROM webhook_subscriptions
+		 WHERE active = 1 AND JSON_CONTAINS(events, JSON_QUOTE(?))`,
+		e.ID, e.Type, payload, e.Type,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("enqueueing deliveries of event %d: %w", e.ID, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("checking rows affected: %w", err)
+	}
+	return n, nil
+}
+
+// Claim takes up to limit pending deliveries that are due, counts an attempt
+// against each and hides them from other claims for lease, within which the
+// caller must report the outcome. A delivery whose sender died is claimed
+// again once the lease runs out. Deliveries to a deactivated subscription stay
+// pending and are sent if it is activated again.
+func (r *mysqlWebhookRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookAttempt, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("beginning delivery claim: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT d.delivery_id, d.event_id, d.event_type, d.attempts + 1, s.url, s.secret, d.payload
+		 FROM webhook_deliveries d
+		 JOIN webhook_subscriptions s ON s.subscription_id = d.subscription_id
+		 WHERE d.status = 'pending' AND d.next_attempt_at <= CURRENT_TIMESTAMP(6) AND s.active = 1
+		 ORDER BY d.next_attempt_at, d.delivery_id
+		 LIMIT ? FOR UPDATE OF d SKIP LOCKED`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claiming deliveries: %w", err)
+	}
+	var (
+		attempts []model.WebhookAttempt
+		args     []interface{}
+	)
+	args = append(args, lease.Microseconds())
+	for rows.Next() {
+		var a model.WebhookAttempt
+		if err := rows.Scan(&a.DeliveryID, &a.EventID, &a.EventType, &a.Attempt, &a.URL, &a.Secret, &a.Payload); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning delivery row: %w", err)
+		}
+		attempts = append(attempts, a)
+		args = append(args, a.DeliveryID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating delivery rows: %w", err)
+	}
+	if len(attempts) == 0 {
+		return nil, nil
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE webhook_deliveries
+		 SET attempts = attempts + 1,
+		     next_attempt_at = CURRENT_TIMESTAMP(6) + INTERVAL ? MICROSECOND
+		 WHERE delivery_id IN (?`+strings.Repeat(", ?", len(attempts)-1)+`)`, args...,
+	); err != nil {
+		return nil, fmt.Errorf("leasing deliveries: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing delivery claim: %w", err)
+	}
+	return attempts, nil
+}
+
+func (r *mysqlWebhookRepo) MarkDelivered(ctx context.Context, id int64, responseStatus int) error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_deliveries
+		 SET status = 'succeeded', response_status = ?, last_error = '', delivered_at = CURRENT_TIMESTAMP(6)
+		 WHERE delivery_id = ?`, responseStatus, id,
+	); err != nil {
+		return fmt.Errorf("marking delivery %d succeeded: %w", id, err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed attempt. The delivery is tried again at retryAt
+// or, when retryAt is nil, moved to the dead-letter list.
+func (r *mysqlWebhookRepo) MarkFailed(ctx context.Context, id int64, responseStatus int, reason string, retryAt *time.Time) error {
+	if len(reason) > 255 {
+		reason = strings.ToValidUTF8(reason[:255], "")
+	}
+	status, next := model.DeliveryDead, time.Now()
+	if retryAt != nil {
+		status, next = model.DeliveryPending, *retryAt
+	}
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_deliveries
+		 SET status = ?, response_status = ?, last_error = ?, next_attempt_at = ?
+		 WHERE delivery_id = ?`, status, responseStatus, reason, next, id,
+	); err != nil {
+		return fmt.Errorf("marking delivery %d failed: %w", id, err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the most recent deliveries, newest first, of one
+// subscription or, when subscriptionID is zero, of all of them. An empty
+// status matches every status.
+func (r *mysqlWebhookRepo) ListDeliveries(ctx context.Context, subscriptionID int64, status model.WebhookDeliveryStatus, limit int) ([]model.WebhookDelivery, error) {
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	query := `SELECT delivery_id, subscription_id, event_id, event_type, status, attempts, next_attempt_at,
+	                 response_status, last_error, created_at, delivered_at
+	          FROM webhook_deliveries`
+	var (
+		conds []string
+		args  []interface{}
+	)
+	if subscriptionID != 0 {
+		conds = append(conds, "subscription_id = ?")
+		args = append(args, subscriptionID)
+	}
+	if status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, status)
+	}
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY delivery_id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []model.WebhookDelivery{}
+	for rows.Next() {
+		var (
+			d         model.WebhookDelivery
+			delivered sql.NullTime
+		)
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.ResponseStatus, &d.LastError, &d.CreatedAt, &delivered); err != nil {
+			return nil, fmt.Errorf("scanning delivery row: %w", err)
+		}
+		if delivered.Valid {
+			d.DeliveredAt = &delivered.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating delivery rows: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Redeliver queues a delivery to be sent again straight away with a fresh
+// set of attempts, whatever its status.
+func (r *mysqlWebhookRepo) Redeliver(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_deliveries
+		 SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP(6), delivered_at = NULL
+		 WHERE delivery_id = ?`, id,
+	)
+	if err != nil {
+		return fmt.Errorf("requeueing delivery %d: %w", id, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("checking rows affected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("delivery %d: %w", id, ErrDeliveryNotFound)
+	}
+	return nil
+}
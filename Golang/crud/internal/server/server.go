@@ -9,11 +9,13 @@ import (
 
 	"golang-sql/internal/config"
 	"golang-sql/internal/handler"
+	"golang-sql/internal/media"
 	"golang-sql/internal/middleware"
 	"golang-sql/internal/repository"
+	"golang-sql/internal/stream"
 )
 
-func New(cfg *config.Config, repo repository.ProductRepository, logger *slog.Logger) (*http.Server, error) {
+func New(cfg *config.Config, repos *repository.Repositories, store media.Store, hub *stream.Hub, logger *slog.Logger) (*http.Server, error) {
 	tmpl, err := template.ParseFiles("web/templates/index.html")
 	if err != nil {
 		return nil, fmt.Errorf("parsing template: %w", err)
@@ -21,17 +23,51 @@ func New(cfg *config.Config, repo repository.ProductRepository, logger *slog.Log
 
 	mux := http.NewServeMux()
 
-	productHandler := handler.NewProductHandler(repo, logger, tmpl)
+	productHandler := handler.NewProductHandler(repos.Products, cfg.Batch, logger, tmpl)
 	productHandler.RegisterRoutes(mux)
 
+	reservationHandler := handler.NewReservationHandler(repos.Reservations, cfg.Reservation, logger)
+	reservationHandler.RegisterRoutes(mux)
+
+	warehouseHandler := handler.NewWarehouseHandler(repos.Warehouses, logger)
+	warehouseHandler.RegisterRoutes(mux)
+
+	purchasingHandler := handler.NewPurchasingHandler(repos.Purchasing, logger)
+	purchasingHandler.RegisterRoutes(mux)
+
+	orderHandler := handler.NewOrderHandler(repos.Orders, logger)
+	orderHandler.RegisterRoutes(mux)
+
+	priceHandler := handler.NewPriceHandler(repos.Prices, logger)
+	priceHandler.RegisterRoutes(mux)
+
+	imageHandler := handler.NewImageHandler(repos.Images, store, cfg.Media, logger)
+	imageHandler.RegisterRoutes(mux)
+
+	categoryHandler := handler.NewCategoryHandler(repos.Categories, logger)
+	categoryHandler.RegisterRoutes(mux)
+
+	importHandler := handler.NewImportHandler(repos.Products, cfg.Import, logger)
+	importHandler.RegisterRoutes(mux)
+
+	exportHandler := handler.NewExportHandler(repos.Products, cfg.Export, logger)
+	exportHandler.RegisterRoutes(mux)
+
+	webhookHandler := handler.NewWebhookHandler(repos.Webhooks, logger)
+	webhookHandler.RegisterRoutes(mux)
+
+	eventHandler := handler.NewEventHandler(hub, cfg.Stream, logger)
+	eventHandler.RegisterRoutes(mux)
+
 	stack := middleware.Chain(mux,
 		middleware.Recovery(logger),
 		middleware.RequestID,
 		middleware.Logger(logger),
+		middleware.Compress(cfg.Compression.MinSize),
 		middleware.SecurityHeaders,
 		middleware.CORS(),
 		middleware.RateLimit(cfg.RateLimit.RequestsPerSecond, cfg.RateLimit.Burst),
-		middleware.Timeout(10*time.Second),
+		middleware.Timeout(10*time.Second, "/api/events"),
 	)
 
 	srv := &http.Server{
@@ -41,6 +77,9 @@ func New(cfg *config.Config, repo repository.ProductRepository, logger *slog.Log
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
+	// Event streams never finish on their own, so Shutdown would wait on
+	// them until it gave up.
+	srv.RegisterOnShutdown(func() { _ = hub.Close() })
 
 	return srv, nil
 }
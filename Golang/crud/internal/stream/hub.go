@@ -0,0 +1,293 @@
+// Package stream broadcasts product change events and catalog stats to
+// clients of the live event stream as Server-Sent Events.
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang-sql/internal/config"
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+const (
+	// ReadyEvent is the first message a client receives. Its ID is the event
+	// the stream carries on from, and its data says whether that is the
+	// client's Last-Event-ID; if not, the client must reload what it shows.
+	ReadyEvent = "ready"
+	// StatsEvent carries the current catalog stats.
+	StatsEvent = "stats"
+)
+
+// Message is one Server-Sent Event. Only messages with an ID move a client's
+// Last-Event-ID. The ID is where a client resumes from, so it is never past
+// an event that may still be followed by one with a lower ID; see Hub.
+type Message struct {
+	ID      int64
+	EventID int64 // the outbox event carried, if any
+	Event   string
+	Data    []byte
+}
+
+// EventMessage is the message for an outbox event, named after its type.
+func EventMessage(e model.Event) (Message, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return Message{}, fmt.Errorf("encoding event %d: %w", e.ID, err)
+	}
+	return Message{ID: e.ID, EventID: e.ID, Event: string(e.Type), Data: data}, nil
+}
+
+// WriteTo writes m in the text/event-stream format. Data must not contain
+// newlines, which JSON from encoding/json never does.
+func (m Message) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	if m.ID != 0 {
+		b.WriteString("id: " + strconv.FormatInt(m.ID, 10) + "\n")
+	}
+	b.WriteString("event: " + m.Event + "\ndata: ")
+	b.Write(m.Data)
+	b.WriteString("\n\n")
+	return b.WriteTo(w)
+}
+
+// Hub follows the outbox and fans each new event out to the connected
+// clients, followed by the catalog stats when they change. Every instance
+// runs its own, so clients see every event whichever instance relays it. A
+// client whose buffer is full is disconnected rather than holding the others
+// up, and picks up where it left off when it reconnects.
+//
+// Event IDs are taken when events are inserted but events only become
+// visible when their transactions commit, which need not be in the same
+// order, so an event may turn up below one already sent. Events are sent as
+// soon as they are seen, but the hub only counts an ID as settled once every
+// ID below it has been seen, or the event above a gap has been waited on for
+// CommitGrace and the gap is taken to be a rolled back insert. Message IDs,
+// and so the point a client resumes from, never run past the settled ID.
+type Hub struct {
+	outbox   repository.OutboxRepository
+	products repository.ProductRepository
+	cfg      config.StreamConfig
+	logger   *slog.Logger
+
+	mu        sync.Mutex
+	subs      map[chan Message]struct{}
+	closed    bool
+	following bool  // whether Run has read where the outbox starts
+	settled   int64 // every event up to this ID has been broadcast
+}
+
+func NewHub(outbox repository.OutboxRepository, products repository.ProductRepository, cfg config.StreamConfig, logger *slog.Logger) *Hub {
+	return &Hub{
+		outbox:   outbox,
+		products: products,
+		cfg:      cfg,
+		logger:   logger,
+		subs:     make(map[chan Message]struct{}),
+	}
+}
+
+// Subscribe returns a channel of the messages broadcast from now on and a
+// function to stop receiving them. The channel is closed if the client falls
+// behind or the hub is closed.
+func (h *Hub) Subscribe() (<-chan Message, func()) {
+	ch := make(chan Message, h.cfg.Buffer)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	h.subs[ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[ch]; ok {
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Backlog returns the messages for the events a client has missed since
+// after, its Last-Event-ID, and the ID it resumes from once it has them,
+// which may be below events it has been sent. When after is zero, or the
+// events after it have been purged or number more than ReplayLimit, there
+// is no backlog and resumed is false. A client should subscribe before
+// asking for its backlog, and skip broadcasts of the events in it.
+func (h *Hub) Backlog(ctx context.Context, after int64) (backlog []Message, resume int64, resumed bool, err error) {
+	first, last, err := h.outbox.Bounds(ctx)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if after == 0 || after > last || (first > 0 && after < first-1) {
+		return nil, h.resumeID(last), false, nil
+	}
+
+	events, err := h.outbox.Since(ctx, after, h.cfg.ReplayLimit+1)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if len(events) > h.cfg.ReplayLimit {
+		return nil, h.resumeID(last), false, nil
+	}
+	for _, e := range events {
+		m, err := EventMessage(e)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		m.ID = h.resumeID(e.ID)
+		backlog = append(backlog, m)
+		last = max(last, e.ID)
+	}
+	return backlog, h.resumeID(last), true, nil
+}
+
+// resumeID is the ID for a message sent with event id: id itself when every
+// event below it has been broadcast, otherwise the settled ID.
+func (h *Hub) resumeID(id int64) int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.following {
+		return id
+	}
+	return min(id, h.settled)
+}
+
+func (h *Hub) settle(id int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.following, h.settled = true, id
+}
+
+// Stats returns the stats message for the catalog as it stands, read from
+// the primary so it is not behind the events already sent.
+func (h *Hub) Stats(ctx context.Context) (Message, error) {
+	s, err := h.products.Stats(repository.ReadPrimary(ctx))
+	if err != nil {
+		return Message{}, err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return Message{}, fmt.Errorf("encoding stats: %w", err)
+	}
+	return Message{Event: StatsEvent, Data: data}, nil
+}
+
+// Run polls the outbox every PollInterval, broadcasting the events that have
+// appeared since the last poll and then the stats if those events changed
+// them. It starts from the newest event when it is called and returns when
+// ctx is done.
+func (h *Hub) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.cfg.PollInterval)
+	defer ticker.Stop()
+
+	var (
+		cursor  int64 // the settled ID
+		started bool
+		// Events above the cursor that have been broadcast, with when they
+		// were first seen.
+		seen  = make(map[int64]time.Time)
+		stats []byte
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !started {
+			_, last, err := h.outbox.Bounds(ctx)
+			if err != nil {
+				h.logger.Error("stream_poll_failed", slog.String("error", err.Error()))
+				continue
+			}
+			cursor, started = last, true
+			h.settle(cursor)
+			continue
+		}
+
+		events, err := h.outbox.Since(ctx, cursor, h.cfg.ReplayLimit)
+		if err != nil {
+			h.logger.Error("stream_poll_failed", slog.String("error", err.Error()))
+			continue
+		}
+		now := time.Now()
+		var fresh []model.Event
+		for _, e := range events {
+			if _, ok := seen[e.ID]; !ok {
+				seen[e.ID] = now
+				fresh = append(fresh, e)
+			}
+		}
+		for _, e := range events {
+			if e.ID != cursor+1 && now.Sub(seen[e.ID]) < h.cfg.CommitGrace {
+				break
+			}
+			cursor = e.ID
+			delete(seen, e.ID)
+		}
+		h.settle(cursor)
+		if len(fresh) == 0 {
+			continue
+		}
+		for _, e := range fresh {
+			m, err := EventMessage(e)
+			if err != nil {
+				h.logger.Error("stream_event_encode_failed", slog.Int64("event_id", e.ID), slog.String("error", err.Error()))
+				continue
+			}
+			m.ID = min(m.ID, cursor)
+			h.broadcast(m)
+		}
+
+		m, err := h.Stats(ctx)
+		if err != nil {
+			h.logger.Error("stream_stats_failed", slog.String("error", err.Error()))
+			continue
+		}
+		if !bytes.Equal(m.Data, stats) {
+			stats = m.Data
+			h.broadcast(m)
+		}
+	}
+}
+
+func (h *Hub) broadcast(m Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- m:
+		default:
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close disconnects every client. Later subscriptions are closed at once.
+func (h *Hub) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-sql/internal/cache"
+	"golang-sql/internal/config"
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+// fakeOutbox serves the events committed so far, in ID order.
+type fakeOutbox struct {
+	repository.OutboxRepository
+
+	mu     sync.Mutex
+	events []model.Event
+}
+
+func (f *fakeOutbox) commit(ids ...int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, id := range ids {
+		i := 0
+		for i < len(f.events) && f.events[i].ID < id {
+			i++
+		}
+		f.events = append(f.events[:i], append([]model.Event{{ID: id, Type: model.EventProductUpdated}}, f.events[i:]...)...)
+	}
+}
+
+func (f *fakeOutbox) Since(_ context.Context, after int64, limit int) ([]model.Event, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var events []model.Event
+	for _, e := range f.events {
+		if e.ID > after && len(events) < limit {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (f *fakeOutbox) Bounds(context.Context) (int64, int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.events) == 0 {
+		return 0, 0, nil
+	}
+	return f.events[0].ID, f.events[len(f.events)-1].ID, nil
+}
+
+type fakeProducts struct {
+	repository.ProductRepository
+}
+
+func (fakeProducts) Stats(context.Context) (*model.Stats, error) {
+	return &model.Stats{}, nil
+}
+
+func receive(t *testing.T, ch <-chan Message, event string) Message {
+	t.Helper()
+	for {
+		select {
+		case m := <-ch:
+			if m.Event == event {
+				return m
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no %s message", event)
+		}
+	}
+}
+
+func TestHubLateCommit(t *testing.T) {
+	outbox := &fakeOutbox{}
+	outbox.commit(10)
+	cfg := config.StreamConfig{PollInterval: time.Millisecond, Buffer: 16, ReplayLimit: 100, CommitGrace: time.Hour}
+	h := NewHub(outbox, fakeProducts{}, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ch, unsubscribe := h.Subscribe()
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go h.Run(ctx)
+	for h.resumeID(1<<62) != 10 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// 12 commits before 11: it is sent at once, but a client must not
+	// resume past the gap.
+	outbox.commit(12)
+	m := receive(t, ch, string(model.EventProductUpdated))
+	if m.EventID != 12 || m.ID != 10 {
+		t.Fatalf("got event %d with ID %d, want event 12 with ID 10", m.EventID, m.ID)
+	}
+
+	outbox.commit(11)
+	m = receive(t, ch, string(model.EventProductUpdated))
+	if m.EventID != 11 || m.ID != 11 {
+		t.Fatalf("got event %d with ID %d, want event 11 with ID 11", m.EventID, m.ID)
+	}
+	for h.resumeID(1<<62) != 12 {
+		time.Sleep(time.Millisecond)
+	}
+
+	backlog, resume, resumed, err := h.Backlog(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resumed || len(backlog) != 2 || resume != 12 {
+		t.Errorf("got backlog of %d resuming from %d (resumed %v), want 2 from 12", len(backlog), resume, resumed)
+	}
+}
+
+func TestHubGapExpires(t *testing.T) {
+	outbox := &fakeOutbox{}
+	outbox.commit(10)
+	cfg := config.StreamConfig{PollInterval: time.Millisecond, Buffer: 16, ReplayLimit: 100, CommitGrace: 20 * time.Millisecond}
+	h := NewHub(outbox, fakeProducts{}, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go h.Run(ctx)
+	for h.resumeID(1<<62) != 10 {
+		time.Sleep(time.Millisecond)
+	}
+
+	outbox.commit(12)
+	deadline := time.Now().Add(2 * time.Second)
+	for h.resumeID(1<<62) != 12 {
+		if time.Now().After(deadline) {
+			t.Fatal("the gap at 11 was never given up on")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// stockProducts reports whatever total stock it was last given, as the
+// database does after a sale that no cache heard about.
+type stockProducts struct {
+	repository.ProductRepository
+	stock atomic.Int64
+}
+
+func (p *stockProducts) Stats(context.Context) (*model.Stats, error) {
+	return &model.Stats{TotalStock: int(p.stock.Load())}, nil
+}
+
+func TestHubStatsBehindCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	products := &stockProducts{}
+	products.stock.Store(5)
+	cached := repository.NewCachedProductRepo(products, cache.NewLRU(16), time.Hour, logger)
+	if _, err := cached.Stats(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	outbox := &fakeOutbox{}
+	outbox.commit(10)
+	cfg := config.StreamConfig{PollInterval: time.Millisecond, Buffer: 16, ReplayLimit: 100, CommitGrace: time.Hour}
+	h := NewHub(outbox, products, cfg, logger)
+	ch, unsubscribe := h.Subscribe()
+	defer unsubscribe()
+	go h.Run(ctx)
+	for h.resumeID(1<<62) != 10 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A sale changes the stock without invalidating the cached stats.
+	products.stock.Store(3)
+	outbox.commit(11)
+	var s model.Stats
+	if err := json.Unmarshal(receive(t, ch, StatsEvent).Data, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.TotalStock != 3 {
+		t.Errorf("got total stock %d, want 3", s.TotalStock)
+	}
+}
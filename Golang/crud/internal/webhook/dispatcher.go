@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang-sql/internal/config"
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+// Dispatcher fans outbox events out to webhook subscriptions and sends the
+// resulting deliveries. It is an outbox sink: publishing an event only queues
+// its deliveries, so a slow or failing receiver never holds up the relay.
+type Dispatcher struct {
+	repo   repository.WebhookRepository
+	cfg    config.WebhookConfig
+	client *http.Client
+	logger *slog.Logger
+}
+
+func NewDispatcher(repo repository.WebhookRepository, cfg config.WebhookConfig, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		repo: repo,
+		cfg:  cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			// A redirect would resend the signed body somewhere the admin
+			// did not register.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		logger: logger,
+	}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, e model.Event) error {
+	_, err := d.repo.Enqueue(ctx, e)
+	return err
+}
+
+func (d *Dispatcher) Close() error {
+	d.client.CloseIdleConnections()
+	return nil
+}
+
+// Deliver sends one batch of due deliveries, several at a time, and returns
+// how many went through and how many failed.
+func (d *Dispatcher) Deliver(ctx context.Context) (sent, failed int, err error) {
+	// The lease outlasts the request timeout, so a claimed delivery is not
+	// picked up again while it is still being sent.
+	attempts, err := d.repo.Claim(ctx, d.cfg.BatchSize, d.cfg.Timeout+30*time.Second)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, max(d.cfg.Concurrency, 1))
+	)
+	for _, a := range attempts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			ok := d.attempt(ctx, a)
+			mu.Lock()
+			if ok {
+				sent++
+			} else {
+				failed++
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return sent, failed, nil
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, a model.WebhookAttempt) bool {
+	status, err := d.send(ctx, a)
+	// The outcome must be recorded even if shutdown cancelled the send.
+	ctx = context.WithoutCancel(ctx)
+	if err == nil {
+		if err := d.repo.MarkDelivered(ctx, a.DeliveryID, status); err != nil {
+			d.logger.Error("webhook_mark_failed", slog.Int64("delivery_id", a.DeliveryID), slog.String("error", err.Error()))
+		}
+		return true
+	}
+
+	var retryAt *time.Time
+	if a.Attempt < d.cfg.MaxAttempts {
+		t := time.Now().Add(Backoff(a.Attempt, d.cfg.BackoffBase, d.cfg.BackoffMax))
+		retryAt = &t
+	}
+	if err := d.repo.MarkFailed(ctx, a.DeliveryID, status, err.Error(), retryAt); err != nil {
+		d.logger.Error("webhook_mark_failed", slog.Int64("delivery_id", a.DeliveryID), slog.String("error", err.Error()))
+	}
+	if retryAt == nil {
+		d.logger.Warn("webhook_dead_lettered",
+			slog.Int64("delivery_id", a.DeliveryID),
+			slog.Int("attempts", a.Attempt),
+			slog.String("error", err.Error()),
+		)
+	}
+	return false
+}
+
+// send POSTs the delivery and returns the response status, with an error for
+// anything but a 2xx response.
+func (d *Dispatcher) send(ctx context.Context, a model.WebhookAttempt) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, bytes.NewReader(a.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "StoreHub-Webhooks/1")
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(a.DeliveryID, 10))
+	req.Header.Set("X-Event-ID", strconv.FormatInt(a.EventID, 10))
+	req.Header.Set("X-Event-Type", string(a.EventType))
+	req.Header.Set(SignatureHeader, Sign(a.Secret, time.Now(), a.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Backoff is how long to wait after the given failed attempt: base doubled
+// for each earlier attempt, capped at limit, with the upper half jittered so
+// that receivers coming back up are not hit by every retry at once.
+func Backoff(attempt int, base, limit time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < limit; i++ {
+		d *= 2
+	}
+	d = min(d, limit)
+	return d/2 + rand.N(d/2+1)
+}
@@ -0,0 +1,147 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-sql/internal/config"
+	"golang-sql/internal/model"
+	"golang-sql/internal/repository"
+)
+
+func TestBackoff(t *testing.T) {
+	base, limit := time.Second, 30*time.Second
+	tests := []struct {
+		attempt int
+		ceiling time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{5, 16 * time.Second},
+		{6, limit},
+		{20, limit},
+	}
+	for _, tt := range tests {
+		for range 50 {
+			d := Backoff(tt.attempt, base, limit)
+			if d < tt.ceiling/2 || d > tt.ceiling {
+				t.Fatalf("attempt %d: got %v, want between %v and %v", tt.attempt, d, tt.ceiling/2, tt.ceiling)
+			}
+		}
+	}
+}
+
+// outcome is what the dispatcher recorded for one delivery.
+type outcome struct {
+	delivered bool
+	status    int
+	retryAt   *time.Time
+}
+
+// fakeWebhookRepo hands out a fixed set of attempts and records outcomes.
+type fakeWebhookRepo struct {
+	repository.WebhookRepository
+
+	attempts []model.WebhookAttempt
+	mu       sync.Mutex
+	outcomes map[int64]outcome
+}
+
+func (f *fakeWebhookRepo) Claim(context.Context, int, time.Duration) ([]model.WebhookAttempt, error) {
+	return f.attempts, nil
+}
+
+func (f *fakeWebhookRepo) MarkDelivered(_ context.Context, id int64, status int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.outcomes[id] = outcome{delivered: true, status: status}
+	return nil
+}
+
+func (f *fakeWebhookRepo) MarkFailed(_ context.Context, id int64, status int, _ string, retryAt *time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.outcomes[id] = outcome{status: status, retryAt: retryAt}
+	return nil
+}
+
+func TestDispatcherDeliver(t *testing.T) {
+	const secret = "whsec_test"
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sig := r.Header.Get(SignatureHeader)
+		ts, _, _ := strings.Cut(strings.TrimPrefix(sig, "t="), ",")
+		unix, _ := strconv.ParseInt(ts, 10, 64)
+		if !hmac.Equal([]byte(sig), []byte(Sign(secret, time.Unix(unix, 0), body))) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/down" {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	attempt := func(id int64, path string, n int) model.WebhookAttempt {
+		return model.WebhookAttempt{
+			DeliveryID: id,
+			EventID:    id,
+			EventType:  model.EventProductUpdated,
+			Attempt:    n,
+			URL:        receiver.URL + path,
+			Secret:     secret,
+			Payload:    []byte(`{"id":` + strconv.FormatInt(id, 10) + `}`),
+		}
+	}
+	repo := &fakeWebhookRepo{
+		attempts: []model.WebhookAttempt{
+			attempt(1, "/ok", 1),
+			attempt(2, "/down", 1),
+			attempt(3, "/down", 3),
+		},
+		outcomes: make(map[int64]outcome),
+	}
+	cfg := config.WebhookConfig{
+		Timeout:     5 * time.Second,
+		MaxAttempts: 3,
+		BackoffBase: time.Minute,
+		BackoffMax:  time.Hour,
+		BatchSize:   10,
+		Concurrency: 2,
+	}
+	d := NewDispatcher(repo, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	defer d.Close()
+
+	start := time.Now()
+	sent, failed, err := d.Deliver(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 || failed != 2 {
+		t.Errorf("got %d sent and %d failed, want 1 and 2", sent, failed)
+	}
+
+	if o := repo.outcomes[1]; !o.delivered || o.status != http.StatusNoContent {
+		t.Errorf("2xx delivery: got %+v, want delivered with 204", o)
+	}
+	if o := repo.outcomes[2]; o.delivered || o.status != http.StatusServiceUnavailable || o.retryAt == nil {
+		t.Errorf("5xx delivery: got %+v, want a retry after 503", o)
+	} else if wait := o.retryAt.Sub(start); wait < cfg.BackoffBase/2 || wait > cfg.BackoffBase+time.Second {
+		t.Errorf("5xx delivery: retry in %v, want about %v", wait, cfg.BackoffBase)
+	}
+	if o := repo.outcomes[3]; o.delivered || o.status != http.StatusServiceUnavailable || o.retryAt != nil {
+		t.Errorf("final attempt: got %+v, want dead-lettered after 503", o)
+	}
+}
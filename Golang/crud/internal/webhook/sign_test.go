@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	at := time.Unix(1700000000, 0)
+
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte("1700000000." + string(body)))
+	want := "t=1700000000,v1=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got := Sign("whsec_test", at, body); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if Sign("other", at, body) == want {
+		t.Error("signature does not depend on the secret")
+	}
+	if Sign("whsec_test", at.Add(time.Second), body) == want {
+		t.Error("signature does not depend on the timestamp")
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	a, b := NewSecret(), NewSecret()
+	if !strings.HasPrefix(a, "whsec_") || len(a) != len("whsec_")+48 {
+		t.Errorf("got %q, want whsec_ and 48 hex digits", a)
+	}
+	if a == b {
+		t.Error("two secrets were equal")
+	}
+}
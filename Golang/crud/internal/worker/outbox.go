@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"golang-sql/internal/outbox"
+	"golang-sql/internal/repository"
+)
+
+// OutboxRelay publishes outbox events to sink every interval, batchSize at a
+// time, going straight on to the next batch while full ones keep coming.
+func OutboxRelay(ctx context.Context, repo repository.OutboxRepository, sink outbox.Sink, interval time.Duration, batchSize int, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				published, err := repo.Relay(ctx, batchSize, sink.Publish)
+				if published > 0 {
+					logger.Info("outbox_events_published", slog.Int("published", published))
+				}
+				if err != nil {
+					logger.Error("outbox_relay_failed", slog.String("error", err.Error()))
+					break
+				}
+				if published < batchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// OutboxPurger deletes outbox events older than retention once an hour.
+func OutboxPurger(ctx context.Context, repo repository.OutboxRepository, retention time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := repo.Purge(ctx, time.Now().Add(-retention))
+			if err != nil {
+				logger.Error("outbox_purge_failed", slog.String("error", err.Error()))
+				continue
+			}
+			if purged > 0 {
+				logger.Info("outbox_events_purged", slog.Int64("purged", purged))
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"golang-sql/internal/repository"
+)
+
+func PriceScheduler(ctx context.Context, repo repository.PriceRepository, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			applied, err := repo.ApplyDue(ctx)
+			if err != nil {
+				logger.Error("price_schedule_failed", slog.String("error", err.Error()))
+				continue
+			}
+			if applied > 0 {
+				logger.Info("scheduled_prices_applied", slog.Int("applied", applied))
+			}
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"golang-sql/internal/repository"
+)
+
+// ReplicaMonitor runs the router's health checks every interval, starting
+// straight away so that healthy replicas join the rotation at startup.
+func ReplicaMonitor(ctx context.Context, router *repository.ReadRouter, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		router.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
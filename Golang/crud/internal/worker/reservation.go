@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"golang-sql/internal/repository"
+)
+
+func ReservationSweeper(ctx context.Context, repo repository.ReservationRepository, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := repo.ReleaseExpired(ctx)
+			if err != nil {
+				logger.Error("reservation_sweep_failed", slog.String("error", err.Error()))
+				continue
+			}
+			if released > 0 {
+				logger.Info("reservations_expired", slog.Int("released", released))
+			}
+		}
+	}
+}
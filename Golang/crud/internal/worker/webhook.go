@@ -0,0 +1,31 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"golang-sql/internal/webhook"
+)
+
+// WebhookDeliverer sends due webhook deliveries every interval.
+func WebhookDeliverer(ctx context.Context, d *webhook.Dispatcher, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sent, failed, err := d.Deliver(ctx)
+			if err != nil {
+				logger.Error("webhook_delivery_failed", slog.String("error", err.Error()))
+				continue
+			}
+			if sent > 0 || failed > 0 {
+				logger.Info("webhooks_delivered", slog.Int("sent", sent), slog.Int("failed", failed))
+			}
+		}
+	}
+}
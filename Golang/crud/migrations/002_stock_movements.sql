@@ -0,0 +1,20 @@
+-- Stock movement ledger
+-- Every change to products.stock_quantity is recorded here together with
+-- the reason and the person who made it.
+
+USE storehub;
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    movement_id   BIGINT AUTO_INCREMENT PRIMARY KEY,
+    product_id    INT NOT NULL,
+    movement_type ENUM('receipt', 'sale', 'adjustment', 'return') NOT NULL,
+    quantity      INT NOT NULL,           -- signed change applied to stock
+    stock_after   INT NOT NULL,           -- on-hand quantity after the movement
+    reason        VARCHAR(255) DEFAULT '',
+    actor         VARCHAR(100) NOT NULL,
+    created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_product_created (product_id, created_at),
+    CONSTRAINT fk_stock_movements_product FOREIGN KEY (product_id)
+        REFERENCES products (product_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,23 @@
+-- Stock reservations
+-- Checkout flows hold quantity for a limited time. Held units are tracked in
+-- products.reserved_quantity so available-to-sell is stock minus reserved,
+-- while stock_quantity itself only changes when a reservation is committed.
+
+USE storehub;
+
+ALTER TABLE products
+    ADD COLUMN reserved_quantity INT NOT NULL DEFAULT 0 AFTER stock_quantity;
+
+CREATE TABLE IF NOT EXISTS reservations (
+    reservation_id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    product_id     INT NOT NULL,
+    quantity       INT NOT NULL,
+    status         ENUM('active', 'committed', 'released', 'expired') NOT NULL DEFAULT 'active',
+    expires_at     TIMESTAMP NOT NULL,
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_status_expires (status, expires_at),  -- supports the expiry sweeper
+    CONSTRAINT fk_reservations_product FOREIGN KEY (product_id)
+        REFERENCES products (product_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,40 @@
+-- Multi-warehouse inventory
+-- Stock is held per location in warehouse_stock; products.stock_quantity is
+-- kept as the sum across all locations. Warehouse 1 is the default location
+-- and receives any existing stock when this migration runs.
+
+USE storehub;
+
+CREATE TABLE IF NOT EXISTS warehouses (
+    warehouse_id INT AUTO_INCREMENT PRIMARY KEY,
+    code         VARCHAR(20) NOT NULL,
+    name         VARCHAR(100) NOT NULL,
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uq_code (code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT IGNORE INTO warehouses (warehouse_id, code, name) VALUES (1, 'MAIN', 'Main store');
+
+CREATE TABLE IF NOT EXISTS warehouse_stock (
+    warehouse_id INT NOT NULL,
+    product_id   INT NOT NULL,
+    quantity     INT NOT NULL DEFAULT 0,
+    updated_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (warehouse_id, product_id),
+    INDEX idx_product (product_id),                 -- supports per-product stock levels
+    CONSTRAINT fk_warehouse_stock_warehouse FOREIGN KEY (warehouse_id)
+        REFERENCES warehouses (warehouse_id),
+    CONSTRAINT fk_warehouse_stock_product FOREIGN KEY (product_id)
+        REFERENCES products (product_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT IGNORE INTO warehouse_stock (warehouse_id, product_id, quantity)
+    SELECT 1, product_id, stock_quantity FROM products WHERE stock_quantity > 0;
+
+ALTER TABLE stock_movements
+    ADD COLUMN warehouse_id INT NOT NULL DEFAULT 1 AFTER product_id,
+    MODIFY movement_type ENUM('receipt', 'sale', 'adjustment', 'return', 'transfer') NOT NULL,
+    ADD CONSTRAINT fk_stock_movements_warehouse FOREIGN KEY (warehouse_id)
+        REFERENCES warehouses (warehouse_id);
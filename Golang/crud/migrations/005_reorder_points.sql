@@ -0,0 +1,9 @@
+-- Per-product reorder points
+-- A product is low on stock once stock_quantity drops to its reorder_point.
+-- Existing rows keep the previous global threshold of 10.
+
+USE storehub;
+
+ALTER TABLE products
+    ADD COLUMN reorder_point    INT NOT NULL DEFAULT 10 AFTER reserved_quantity,
+    ADD COLUMN reorder_quantity INT NOT NULL DEFAULT 0 AFTER reorder_point;
@@ -0,0 +1,46 @@
+-- Suppliers and purchase orders
+-- Purchase orders move draft -> sent -> partially_received -> received.
+-- Receiving goods books a 'receipt' stock movement into the order's warehouse.
+
+USE storehub;
+
+CREATE TABLE IF NOT EXISTS suppliers (
+    supplier_id INT AUTO_INCREMENT PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL,
+    email       VARCHAR(255) DEFAULT '',
+    phone       VARCHAR(30) DEFAULT '',
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    po_id        BIGINT AUTO_INCREMENT PRIMARY KEY,
+    supplier_id  INT NOT NULL,
+    warehouse_id INT NOT NULL,                      -- where received goods are booked
+    status       ENUM('draft', 'sent', 'partially_received', 'received', 'cancelled') NOT NULL DEFAULT 'draft',
+    notes        VARCHAR(255) DEFAULT '',
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_status (status),
+    CONSTRAINT fk_purchase_orders_supplier FOREIGN KEY (supplier_id)
+        REFERENCES suppliers (supplier_id),
+    CONSTRAINT fk_purchase_orders_warehouse FOREIGN KEY (warehouse_id)
+        REFERENCES warehouses (warehouse_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS purchase_order_items (
+    item_id           BIGINT AUTO_INCREMENT PRIMARY KEY,
+    po_id             BIGINT NOT NULL,
+    product_id        INT NOT NULL,
+    quantity_ordered  INT NOT NULL,
+    quantity_received INT NOT NULL DEFAULT 0,
+    unit_cost         DECIMAL(12,2) NOT NULL DEFAULT 0.00,
+
+    UNIQUE KEY uq_po_product (po_id, product_id),
+    CONSTRAINT fk_po_items_po FOREIGN KEY (po_id)
+        REFERENCES purchase_orders (po_id) ON DELETE CASCADE,
+    CONSTRAINT fk_po_items_product FOREIGN KEY (product_id)
+        REFERENCES products (product_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
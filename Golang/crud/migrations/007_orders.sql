@@ -0,0 +1,37 @@
+-- Sales orders
+-- Orders deduct stock from their warehouse when created ('sale' movements)
+-- and restock it when cancelled ('return' movements). Line items keep a
+-- snapshot of the product name and price at the time of sale.
+
+USE storehub;
+
+CREATE TABLE IF NOT EXISTS orders (
+    order_id     BIGINT AUTO_INCREMENT PRIMARY KEY,
+    reference    VARCHAR(100) DEFAULT '',            -- external sale/receipt number
+    warehouse_id INT NOT NULL,
+    status       ENUM('pending', 'paid', 'fulfilled', 'cancelled') NOT NULL DEFAULT 'pending',
+    total        DECIMAL(12,2) NOT NULL DEFAULT 0.00,
+    actor        VARCHAR(100) NOT NULL,
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_status (status),
+    INDEX idx_created_at (created_at),
+    CONSTRAINT fk_orders_warehouse FOREIGN KEY (warehouse_id)
+        REFERENCES warehouses (warehouse_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS order_items (
+    item_id    BIGINT AUTO_INCREMENT PRIMARY KEY,
+    order_id   BIGINT NOT NULL,
+    product_id INT NOT NULL,
+    name       VARCHAR(100) NOT NULL,               -- product name at time of sale
+    quantity   INT NOT NULL,
+    unit_price DECIMAL(12,2) NOT NULL,              -- product price at time of sale
+
+    UNIQUE KEY uq_order_product (order_id, product_id),
+    CONSTRAINT fk_order_items_order FOREIGN KEY (order_id)
+        REFERENCES orders (order_id) ON DELETE CASCADE,
+    CONSTRAINT fk_order_items_product FOREIGN KEY (product_id)
+        REFERENCES products (product_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
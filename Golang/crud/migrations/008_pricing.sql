@@ -0,0 +1,39 @@
+-- Price history and scheduled price changes
+-- Every price a product has had is appended to price_history. Managers can
+-- queue a future price in scheduled_prices; a background worker applies it
+-- once effective_at has passed.
+
+USE storehub;
+
+CREATE TABLE IF NOT EXISTS price_history (
+    price_change_id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    product_id      INT NOT NULL,
+    price           DECIMAL(12,2) NOT NULL,
+    source          ENUM('create', 'update', 'schedule') NOT NULL,
+    actor           VARCHAR(100) DEFAULT '',
+    changed_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_product_changed (product_id, changed_at),
+    CONSTRAINT fk_price_history_product FOREIGN KEY (product_id)
+        REFERENCES products (product_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Start every existing product's history with its current price
+INSERT INTO price_history (product_id, price, source, changed_at)
+    SELECT product_id, price, 'create', created_at FROM products;
+
+CREATE TABLE IF NOT EXISTS scheduled_prices (
+    schedule_id  BIGINT AUTO_INCREMENT PRIMARY KEY,
+    product_id   INT NOT NULL,
+    price        DECIMAL(12,2) NOT NULL,
+    effective_at TIMESTAMP NOT NULL,
+    status       ENUM('pending', 'applied', 'cancelled') NOT NULL DEFAULT 'pending',
+    actor        VARCHAR(100) NOT NULL,
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    applied_at   TIMESTAMP NULL DEFAULT NULL,
+
+    INDEX idx_status_effective (status, effective_at),  -- supports the price worker
+    INDEX idx_product (product_id),
+    CONSTRAINT fk_scheduled_prices_product FOREIGN KEY (product_id)
+        REFERENCES products (product_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
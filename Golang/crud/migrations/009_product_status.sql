@@ -0,0 +1,9 @@
+-- Product lifecycle status
+-- draft -> active -> discontinued -> archived, with discontinued products able
+-- to be reactivated. Existing rows are treated as active.
+
+USE storehub;
+
+ALTER TABLE products
+    ADD COLUMN status ENUM('draft','active','discontinued','archived') NOT NULL DEFAULT 'active' AFTER reorder_quantity,
+    ADD INDEX idx_status (status);
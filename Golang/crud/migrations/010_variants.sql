@@ -0,0 +1,16 @@
+-- Product variants
+-- A variant is a product row pointing at its parent through parent_id. It has
+-- its own SKU and stock; price follows the parent unless price_override is set.
+-- Parents cannot be deleted while they still have variants.
+
+USE storehub;
+
+ALTER TABLE products
+    ADD COLUMN parent_id      INT NULL AFTER product_id,
+    ADD COLUMN sku            VARCHAR(64) NULL AFTER parent_id,
+    ADD COLUMN options        JSON NULL AFTER description,
+    ADD COLUMN price_override DECIMAL(12,2) NULL AFTER price,
+    ADD UNIQUE INDEX uq_sku (sku),
+    ADD INDEX idx_parent (parent_id),
+    ADD CONSTRAINT fk_products_parent FOREIGN KEY (parent_id)
+        REFERENCES products (product_id);
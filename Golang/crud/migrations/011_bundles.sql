@@ -0,0 +1,20 @@
+-- Bundles and kits
+-- A bundle is a product flagged is_bundle whose stock is derived from its
+-- components. Selling a bundle deducts each component; components cannot be
+-- deleted while a bundle still uses them.
+
+USE storehub;
+
+ALTER TABLE products ADD COLUMN is_bundle TINYINT(1) NOT NULL DEFAULT 0 AFTER status;
+
+CREATE TABLE IF NOT EXISTS bundle_components (
+    bundle_id    INT NOT NULL,
+    component_id INT NOT NULL,
+    quantity     INT NOT NULL,
+    PRIMARY KEY (bundle_id, component_id),
+    INDEX idx_component (component_id),
+    CONSTRAINT fk_bundle_components_bundle FOREIGN KEY (bundle_id)
+        REFERENCES products (product_id) ON DELETE CASCADE,
+    CONSTRAINT fk_bundle_components_component FOREIGN KEY (component_id)
+        REFERENCES products (product_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
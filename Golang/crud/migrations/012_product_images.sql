@@ -0,0 +1,22 @@
+-- Product images
+-- Files live in the media store (local disk by default); rows keep the keys
+-- of the original and its thumbnail. Rows go when their product is deleted;
+-- the stored files are left in place.
+
+USE storehub;
+
+CREATE TABLE IF NOT EXISTS product_images (
+    image_id      BIGINT AUTO_INCREMENT PRIMARY KEY,
+    product_id    INT NOT NULL,
+    storage_key   VARCHAR(255) NOT NULL,
+    thumbnail_key VARCHAR(255) NOT NULL,
+    content_type  VARCHAR(50) NOT NULL,
+    size_bytes    BIGINT NOT NULL,
+    width         INT NOT NULL,
+    height        INT NOT NULL,
+    position      INT NOT NULL DEFAULT 0,
+    created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_product_position (product_id, position),
+    CONSTRAINT fk_product_images_product FOREIGN KEY (product_id)
+        REFERENCES products (product_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,33 @@
+-- Categories and typed product attributes
+-- A category carries an attribute schema (JSON list of name, type, unit,
+-- required, values) that products in it must match. Attribute values are
+-- stored one row each so they can be filtered; numbers are also kept in
+-- num_value for range queries.
+
+USE storehub;
+
+CREATE TABLE IF NOT EXISTS categories (
+    category_id      INT AUTO_INCREMENT PRIMARY KEY,
+    name             VARCHAR(100) NOT NULL,
+    attribute_schema JSON NOT NULL,
+    created_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX uq_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE products
+    ADD COLUMN category_id INT NULL AFTER options,
+    ADD CONSTRAINT fk_products_category FOREIGN KEY (category_id)
+        REFERENCES categories (category_id);
+
+CREATE TABLE IF NOT EXISTS product_attributes (
+    product_id INT NOT NULL,
+    name       VARCHAR(50) NOT NULL,
+    value_type ENUM('string','number','boolean') NOT NULL,
+    value      VARCHAR(255) NOT NULL,
+    num_value  DECIMAL(20,6) NULL,
+    PRIMARY KEY (product_id, name),
+    INDEX idx_name_value (name, value),
+    INDEX idx_name_num (name, num_value),
+    CONSTRAINT fk_product_attributes_product FOREIGN KEY (product_id)
+        REFERENCES products (product_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,10 @@
+-- Precise product modification times
+-- updated_at backs the ETag and Last-Modified validators of product reads, so
+-- it needs to tell apart changes made within the same second. Writes that
+-- only touch related rows (attributes, images, bundle components) set it
+-- explicitly.
+
+USE storehub;
+
+ALTER TABLE products
+    MODIFY updated_at TIMESTAMP(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6);
@@ -0,0 +1,21 @@
+-- Transactional outbox of product change events
+-- Every product create, update and delete appends an event here in the same
+-- transaction, so an event exists exactly when the change committed. A relay
+-- publishes unpublished events in event_id order and stamps published_at;
+-- events past the retention period are purged. product_id carries no foreign
+-- key, since deletion events outlive their product.
+
+USE storehub;
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    event_id     BIGINT AUTO_INCREMENT PRIMARY KEY,
+    event_type   VARCHAR(50) NOT NULL,
+    product_id   INT NOT NULL,
+    payload      JSON NULL,
+    created_at   TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    published_at TIMESTAMP(6) NULL,
+    attempts     INT NOT NULL DEFAULT 0,
+    last_error   VARCHAR(255) NOT NULL DEFAULT '',
+    INDEX idx_unpublished (published_at, event_id),
+    INDEX idx_created (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
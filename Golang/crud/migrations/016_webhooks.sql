@@ -0,0 +1,38 @@
+-- Outgoing webhooks
+-- Subscriptions name a URL, the event types it wants and the secret its
+-- deliveries are signed with. Each outbox event becomes one delivery per
+-- matching active subscription; a delivery is retried with backoff until it
+-- succeeds or runs out of attempts, when it is left as 'dead' for an admin to
+-- inspect and redeliver. The rows double as the delivery log.
+
+USE storehub;
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    subscription_id INT AUTO_INCREMENT PRIMARY KEY,
+    url             VARCHAR(500) NOT NULL,
+    secret          VARCHAR(100) NOT NULL,
+    events          JSON NOT NULL,
+    active          TINYINT(1) NOT NULL DEFAULT 1,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    delivery_id     BIGINT AUTO_INCREMENT PRIMARY KEY,
+    subscription_id INT NOT NULL,
+    event_id        BIGINT NOT NULL,
+    event_type      VARCHAR(50) NOT NULL,
+    payload         JSON NOT NULL,
+    status          ENUM('pending', 'succeeded', 'dead') NOT NULL DEFAULT 'pending',
+    attempts        INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    response_status INT NOT NULL DEFAULT 0,
+    last_error      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at      TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    delivered_at    TIMESTAMP(6) NULL,
+    UNIQUE INDEX uq_subscription_event (subscription_id, event_id),
+    INDEX idx_due (status, next_attempt_at),
+    INDEX idx_subscription_created (subscription_id, delivery_id),
+    CONSTRAINT fk_webhook_deliveries_subscription FOREIGN KEY (subscription_id)
+        REFERENCES webhook_subscriptions (subscription_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;